@@ -0,0 +1,128 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Now is the clock to fill the audit time fields.
+var Now = time.Now
+
+type userIdKey struct{}
+
+// WithUserId returns a copy of ctx carrying the id of
+// the current user to fill the createdBy/updatedBy fields.
+func WithUserId(ctx context.Context, userId any) context.Context {
+	return context.WithValue(ctx, userIdKey{}, userId)
+}
+
+// GetUserId returns the id of the current user in ctx, or nil if absent.
+func GetUserId(ctx context.Context) any {
+	return ctx.Value(userIdKey{})
+}
+
+// AuditFields holds the names of the fields tagged by
+// `createdAt`, `updatedAt`, `createdBy` and `updatedBy`.
+type AuditFields struct {
+	CreatedAt, UpdatedAt, CreatedBy, UpdatedBy string
+}
+
+func BuildAuditFields(entityType reflect.Type) AuditFields {
+	af := AuditFields{}
+	for i := 0; i < entityType.NumField(); i++ {
+		field := entityType.Field(i)
+		tag := field.Tag
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			af = af.merge(BuildAuditFields(field.Type))
+		} else if _, ok := tag.Lookup("createdAt"); ok {
+			af.CreatedAt = field.Name
+		} else if _, ok = tag.Lookup("updatedAt"); ok {
+			af.UpdatedAt = field.Name
+		} else if _, ok = tag.Lookup("createdBy"); ok {
+			af.CreatedBy = field.Name
+		} else if _, ok = tag.Lookup("updatedBy"); ok {
+			af.UpdatedBy = field.Name
+		}
+	}
+	return af
+}
+
+func (af AuditFields) merge(other AuditFields) AuditFields {
+	af.CreatedAt = Ternary(af.CreatedAt == "", other.CreatedAt, af.CreatedAt)
+	af.UpdatedAt = Ternary(af.UpdatedAt == "", other.UpdatedAt, af.UpdatedAt)
+	af.CreatedBy = Ternary(af.CreatedBy == "", other.CreatedBy, af.CreatedBy)
+	af.UpdatedBy = Ternary(af.UpdatedBy == "", other.UpdatedBy, af.UpdatedBy)
+	return af
+}
+
+// IsCreated reports whether the field is filled only on creation.
+func (af AuditFields) IsCreated(fieldName string) bool {
+	return fieldName != "" && (fieldName == af.CreatedAt || fieldName == af.CreatedBy)
+}
+
+// FillCreated fills all the audit fields of the entity pointed by self.
+func (af AuditFields) FillCreated(ctx context.Context, self any) {
+	if af == (AuditFields{}) {
+		return
+	}
+	now := Now()
+	rv := reflect.ValueOf(self).Elem()
+	setAuditValue(rv, af.CreatedAt, now)
+	setAuditValue(rv, af.UpdatedAt, now)
+	if userId := GetUserId(ctx); userId != nil {
+		setAuditValue(rv, af.CreatedBy, userId)
+		setAuditValue(rv, af.UpdatedBy, userId)
+	}
+}
+
+// FillUpdated fills the updatedAt and updatedBy
+// fields of the entity pointed by self.
+func (af AuditFields) FillUpdated(ctx context.Context, self any) {
+	rv := reflect.ValueOf(self).Elem()
+	setAuditValue(rv, af.UpdatedAt, Now())
+	if userId := GetUserId(ctx); userId != nil {
+		setAuditValue(rv, af.UpdatedBy, userId)
+	}
+}
+
+func setAuditValue(rv reflect.Value, fieldName string, value any) {
+	if fieldName == "" {
+		return
+	}
+	field := rv.FieldByName(fieldName)
+	if field.IsValid() {
+		setFieldValue(field, value)
+	}
+}
+
+// setFieldValue sets value converted to the type of field,
+// and reports false when value is not convertible to it.
+func setFieldValue(field reflect.Value, value any) bool {
+	fieldType := field.Type()
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || !v.Type().ConvertibleTo(fieldType) || (fieldType.Kind() == reflect.String) != (v.Kind() == reflect.String) {
+		return false
+	}
+	v = v.Convert(fieldType)
+	if field.Kind() == reflect.Ptr {
+		p := reflect.New(fieldType)
+		p.Elem().Set(v)
+		v = p
+	}
+	field.Set(v)
+	return true
+}
@@ -0,0 +1,66 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type auditEntity struct {
+	IntId
+	CreatedAt time.Time  `createdAt:""`
+	UpdatedAt *time.Time `updatedAt:""`
+	CreatedBy *int64     `createdBy:""`
+	UpdatedBy string     `updatedBy:""`
+}
+
+func TestAuditFields(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	defer func(clock func() time.Time) { Now = clock }(Now)
+	Now = func() time.Time { return now }
+	af := BuildAuditFields(reflect.TypeOf(auditEntity{}))
+
+	t.Run("Build audit fields by tags", func(t *testing.T) {
+		expect := AuditFields{"CreatedAt", "UpdatedAt", "CreatedBy", "UpdatedBy"}
+		if af != expect {
+			t.Errorf("BuildAuditFields() = %v, want %v", af, expect)
+		}
+	})
+
+	t.Run("Fill all the fields on creation", func(t *testing.T) {
+		entity := auditEntity{}
+		af.FillCreated(WithUserId(context.Background(), 7), &entity)
+		expect := auditEntity{CreatedAt: now, UpdatedAt: &now, CreatedBy: P(int64(7))}
+		if !reflect.DeepEqual(entity, expect) {
+			t.Errorf("FillCreated() = %v, want %v", entity, expect)
+		}
+	})
+
+	t.Run("Fill the updated fields", func(t *testing.T) {
+		entity := auditEntity{IntId: IntId{Id: 1}}
+		af.FillUpdated(WithUserId(context.Background(), "f0rb"), &entity)
+		expect := auditEntity{IntId: IntId{Id: 1}, UpdatedAt: &now, UpdatedBy: "f0rb"}
+		if !reflect.DeepEqual(entity, expect) {
+			t.Errorf("FillUpdated() = %v, want %v", entity, expect)
+		}
+	})
+
+	t.Run("Skip the user fields without the user in ctx", func(t *testing.T) {
+		entity := auditEntity{}
+		af.FillUpdated(context.Background(), &entity)
+		if !(entity.UpdatedAt.Equal(now) && entity.UpdatedBy == "") {
+			t.Errorf("FillUpdated() = %v", entity)
+		}
+	})
+}
@@ -0,0 +1,273 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheStore stores the results of a DataAccess by the keys,
+// which is implemented by the in-memory LRUCache or a store
+// backed by a Redis-like server with the keys in a namespace.
+type CacheStore interface {
+	Get(key string) (any, bool)
+	Set(key string, value any)
+	// Clear removes all the results of the DataAccess.
+	Clear()
+}
+
+// CacheOption configures the operations cached by CacheInterceptor.
+type CacheOption func(options *cacheOptions)
+
+type cacheOptions struct {
+	query bool
+}
+
+// WithQueryCache caches the results of Query, Count and
+// Page by the canonical key of the query as well.
+func WithQueryCache() CacheOption {
+	return func(options *cacheOptions) {
+		options.query = true
+	}
+}
+
+var writeOperations = map[string]bool{
+	"Create": true, "CreateMulti": true, "Update": true, "Patch": true, "PatchByQuery": true,
+	"Delete": true, "DeleteByQuery": true, "Upsert": true, "UpsertMulti": true,
+}
+
+// Cache decorates da by CacheInterceptor with store.
+func Cache[E Entity](da DataAccess[E], store CacheStore, opts ...CacheOption) DataAccess[E] {
+	return Wrap(da, CacheInterceptor(store, opts...))
+}
+
+// CacheInterceptor caches the results of Get by the id, and the
+// results of Query, Count and Page with WithQueryCache, under the
+// keys prefixed by the tenant in ctx. Any write clears store, and
+// again after the completion of the transaction in ctx. The reads
+// in a transaction bypass the cache since they may see the changes
+// not committed yet.
+//
+// A read overlapping a write does not put its result into store,
+// which is tracked by a generation counted in the interceptor, so
+// only the writes through the same interceptor are noticed. The
+// results read by the other processes sharing store may still be
+// stale until the next write or the expiration of the entries.
+func CacheInterceptor(store CacheStore, opts ...CacheOption) Interceptor {
+	options := cacheOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	var mu sync.Mutex
+	var generation uint64
+	invalidate := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		generation++
+		store.Clear()
+	}
+	return func(ctx context.Context, inv *Invocation, next func(ctx context.Context) (any, error)) (any, error) {
+		if writeOperations[inv.Operation] {
+			result, err := next(ctx)
+			invalidate()
+			AfterCompletion(ctx, invalidate)
+			return result, err
+		}
+		key, ok := options.buildKey(ctx, inv)
+		if _, inTx := ReadTransaction(ctx); !ok || inTx {
+			return next(ctx)
+		}
+		if cached, ok := store.Get(key); ok {
+			return cloneResult(cached), nil
+		}
+		mu.Lock()
+		started := generation
+		mu.Unlock()
+		result, err := next(ctx)
+		if err == nil {
+			mu.Lock()
+			if started == generation {
+				store.Set(key, cloneResult(result))
+			}
+			mu.Unlock()
+		}
+		return result, err
+	}
+}
+
+func (o cacheOptions) buildKey(ctx context.Context, inv *Invocation) (key string, ok bool) {
+	switch inv.Operation {
+	case "Get":
+		key, ok = "Get:"+fmt.Sprint(inv.Args[0]), true
+	case "Query", "Count", "Page":
+		key, ok = inv.Operation+":"+CacheKey(inv.Args[0]), o.query
+	}
+	if tenantId := GetTenantId(ctx); tenantId != nil {
+		key = fmt.Sprint(tenantId) + "/" + key
+	}
+	return key, ok
+}
+
+// CacheKey builds the canonical key of query from the
+// values of the fields, which omits the zero values.
+func CacheKey(query any) string {
+	sb := &strings.Builder{}
+	writeCacheKey(sb, reflect.ValueOf(query))
+	return sb.String()
+}
+
+func writeCacheKey(sb *strings.Builder, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			sb.WriteString("nil")
+		} else {
+			writeCacheKey(sb, value.Elem())
+		}
+	case reflect.Struct:
+		sb.WriteString(value.Type().Name() + "{")
+		for i := 0; i < value.NumField(); i++ {
+			if field := value.Field(i); !field.IsZero() {
+				sb.WriteString(value.Type().Field(i).Name + ":")
+				writeCacheKey(sb, field)
+				sb.WriteString(",")
+			}
+		}
+		sb.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		sb.WriteString("[")
+		for i := 0; i < value.Len(); i++ {
+			writeCacheKey(sb, value.Index(i))
+			sb.WriteString(",")
+		}
+		sb.WriteString("]")
+	case reflect.Invalid:
+		sb.WriteString("nil")
+	default:
+		_, _ = fmt.Fprint(sb, value)
+	}
+}
+
+// cloneResult copies the entity pointed by the result and the
+// slices of the result, so the caller can not modify the cached one.
+func cloneResult(result any) any {
+	if result == nil {
+		return nil
+	}
+	return cloneValue(reflect.ValueOf(result)).Interface()
+}
+
+func cloneValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+		p := reflect.New(value.Type().Elem())
+		p.Elem().Set(value.Elem())
+		return p
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		s := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(s, value)
+		return s
+	case reflect.Struct:
+		s := reflect.New(value.Type()).Elem()
+		s.Set(value)
+		for i := 0; i < s.NumField(); i++ {
+			if field := s.Field(i); field.Kind() == reflect.Slice && field.CanSet() {
+				field.Set(cloneValue(field))
+			}
+		}
+		return s
+	}
+	return value
+}
+
+type lruEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+// LRUCache is an in-memory CacheStore, which evicts the least
+// recently used entry beyond the capacity and the expired entries.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+// NewLRUCache creates an LRUCache holding at most capacity entries,
+// each of which expires after ttl, or never when ttl is zero.
+func NewLRUCache(capacity int, ttl time.Duration) *LRUCache {
+	return &LRUCache{capacity: capacity, ttl: ttl, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+func (c *LRUCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if c.ttl > 0 && Now().After(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *LRUCache) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &lruEntry{key: key, value: value, expires: Now().Add(c.ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRUCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*list.Element{}
+	c.order.Init()
+}
+
+// Len returns the number of the entries including the expired ones.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRUCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
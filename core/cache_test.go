@@ -0,0 +1,202 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type countingDataAccess struct {
+	DataAccess[IntId]
+	calls int
+	onGet func()
+}
+
+func (s *countingDataAccess) Get(ctx context.Context, id any) (*IntId, error) {
+	s.calls++
+	if s.onGet != nil {
+		s.onGet()
+	}
+	if GetTenantId(ctx) == 2 {
+		return nil, ErrNotFound
+	}
+	return &IntId{Id: id.(int)}, nil
+}
+
+func (s *countingDataAccess) Count(_ context.Context, _ Query) (int64, error) {
+	s.calls++
+	return 5, nil
+}
+
+func (s *countingDataAccess) Delete(_ context.Context, _ any) (int64, error) {
+	return 1, nil
+}
+
+type stubTransactionContext struct {
+	context.Context
+	callbacks []func()
+}
+
+func (t *stubTransactionContext) Value(key any) any {
+	if key == (TxKey{}) {
+		return t
+	}
+	return t.Context.Value(key)
+}
+
+func (t *stubTransactionContext) Commit() error {
+	for _, callback := range t.callbacks {
+		callback()
+	}
+	return nil
+}
+
+func (t *stubTransactionContext) Rollback() error           { return nil }
+func (t *stubTransactionContext) Parent() context.Context   { return t.Context }
+func (t *stubTransactionContext) SavePoint(string) error    { return nil }
+func (t *stubTransactionContext) RollbackTo(string) error   { return nil }
+func (t *stubTransactionContext) AfterCompletion(fn func()) { t.callbacks = append(t.callbacks, fn) }
+
+type cacheQuery struct {
+	PageQuery
+	IdIn    *[]int
+	ScoreLt *int
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Cache Get until the write", func(t *testing.T) {
+		stub := &countingDataAccess{}
+		da := Cache[IntId](stub, NewLRUCache(10, 0))
+		e1, _ := da.Get(ctx, 1)
+		e1.Id = 100
+		e2, _ := da.Get(ctx, 1)
+		if stub.calls != 1 || e2.Id != 1 {
+			t.Errorf("Get() = %v with %d calls", e2, stub.calls)
+		}
+		_, _ = da.Delete(ctx, 2)
+		_, _ = da.Get(ctx, 1)
+		if stub.calls != 2 {
+			t.Errorf("Should be invalidated by Delete: %d calls", stub.calls)
+		}
+	})
+
+	t.Run("Cache Get by the tenant in ctx", func(t *testing.T) {
+		stub := &countingDataAccess{}
+		da := Cache[IntId](stub, NewLRUCache(10, 0))
+		_, _ = da.Get(WithTenantId(ctx, 1), 2)
+		if e, err := da.Get(WithTenantId(ctx, 2), 2); err != ErrNotFound || e != nil || stub.calls != 2 {
+			t.Errorf("Get() = %v, %v with %d calls", e, err, stub.calls)
+		}
+	})
+
+	t.Run("Skip caching the result read during a write", func(t *testing.T) {
+		stub := &countingDataAccess{}
+		da := Cache[IntId](stub, NewLRUCache(10, 0))
+		stub.onGet = func() {
+			stub.onGet = nil
+			_, _ = da.Delete(ctx, 1)
+		}
+		_, _ = da.Get(ctx, 1)
+		_, _ = da.Get(ctx, 1)
+		if stub.calls != 2 {
+			t.Errorf("Should not cache the stale result: %d calls", stub.calls)
+		}
+	})
+
+	t.Run("Cache Count only with WithQueryCache", func(t *testing.T) {
+		stub := &countingDataAccess{}
+		da := Cache[IntId](stub, NewLRUCache(10, 0))
+		_, _ = da.Count(ctx, cacheQuery{ScoreLt: P(60)})
+		_, _ = da.Count(ctx, cacheQuery{ScoreLt: P(60)})
+		if stub.calls != 2 {
+			t.Errorf("Should not cache Count: %d calls", stub.calls)
+		}
+
+		da = Cache[IntId](stub, NewLRUCache(10, 0), WithQueryCache())
+		_, _ = da.Count(ctx, cacheQuery{ScoreLt: P(60)})
+		_, _ = da.Count(ctx, &cacheQuery{ScoreLt: P(60)})
+		_, _ = da.Count(ctx, cacheQuery{ScoreLt: P(70)})
+		if stub.calls != 4 {
+			t.Errorf("Should cache Count by the query: %d calls", stub.calls)
+		}
+	})
+
+	t.Run("Bypass the cache in transaction and invalidate after completion", func(t *testing.T) {
+		stub := &countingDataAccess{}
+		store := NewLRUCache(10, 0)
+		da := Cache[IntId](stub, store)
+		tc := &stubTransactionContext{Context: ctx}
+
+		_, _ = da.Get(WithUserId(tc, 1), 1)
+		_, _ = da.Delete(tc, 2)
+		_, _ = da.Get(ctx, 1)
+		if stub.calls != 2 || store.Len() != 1 {
+			t.Errorf("Should not cache in tx: %d calls, %d entries", stub.calls, store.Len())
+		}
+		_ = tc.Commit()
+		if store.Len() != 0 {
+			t.Errorf("Should be invalidated after commit: %d entries", store.Len())
+		}
+	})
+}
+
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		query  any
+		expect string
+	}{
+		{cacheQuery{}, "cacheQuery{}"},
+		{&cacheQuery{ScoreLt: P(60), IdIn: &[]int{1, 2}}, "cacheQuery{IdIn:[1,2,],ScoreLt:60,}"},
+		{cacheQuery{PageQuery: PageQuery{Page: 2, Sort: "id,desc"}}, "cacheQuery{PageQuery:PageQuery{Page:2,Sort:id,desc,},}"},
+	}
+	for _, tt := range tests {
+		if actual := CacheKey(tt.query); actual != tt.expect {
+			t.Errorf("CacheKey() = %s, want %s", actual, tt.expect)
+		}
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	defer func(clock func() time.Time) { Now = clock }(Now)
+	Now = func() time.Time { return now }
+
+	t.Run("Evict the least recently used entry", func(t *testing.T) {
+		cache := NewLRUCache(2, 0)
+		cache.Set("a", 1)
+		cache.Set("b", 2)
+		cache.Get("a")
+		cache.Set("c", 3)
+		if _, ok := cache.Get("b"); ok || cache.Len() != 2 {
+			t.Errorf("Should evict b: %d entries", cache.Len())
+		}
+		if v, ok := cache.Get("a"); !ok || v != 1 {
+			t.Errorf("Get() = %v, %v", v, ok)
+		}
+	})
+
+	t.Run("Expire the entry after ttl", func(t *testing.T) {
+		cache := NewLRUCache(2, time.Minute)
+		cache.Set("a", 1)
+		now = now.Add(30 * time.Second)
+		if _, ok := cache.Get("a"); !ok {
+			t.Error("Should not expire before ttl")
+		}
+		now = now.Add(time.Minute)
+		if _, ok := cache.Get("a"); ok || cache.Len() != 0 {
+			t.Error("Should expire after ttl")
+		}
+	})
+}
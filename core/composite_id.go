@@ -0,0 +1,120 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidId is returned when an id can't be resolved
+// to the values of the id fields of the entity.
+var ErrInvalidId = errors.New("invalid id")
+
+// FindIdFields returns the fields of the id of the entity type,
+// which are more than one for the entity with a composite key.
+func FindIdFields(entityType reflect.Type) []FieldMetadata {
+	idFields := make([]FieldMetadata, 0, 2)
+	for _, md := range BuildFieldMetas(entityType) {
+		if md.IsId {
+			idFields = append(idFields, md)
+		}
+	}
+	return idFields
+}
+
+// ReadIdValues resolves the composite id to the values of idFields
+// in order. The id is a key struct with the fields named after
+// idFields, a slice of the values, or the values joined by commas,
+// e.g. "1,2", which are parsed to the types of idFields.
+func ReadIdValues(id any, idFields []FieldMetadata) ([]any, error) {
+	rv := reflect.Indirect(reflect.ValueOf(id))
+	values := make([]any, len(idFields))
+	switch rv.Kind() {
+	case reflect.Struct:
+		for i, md := range idFields {
+			field := rv.FieldByName(md.Field.Name)
+			if !field.IsValid() {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidId, id)
+			}
+			values[i] = ReadValue(field)
+		}
+	case reflect.String:
+		parts := strings.Split(rv.String(), ",")
+		if len(parts) != len(idFields) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidId, id)
+		}
+		for i, md := range idFields {
+			value, err := parseValue(parts[i], md.Field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidId, id)
+			}
+			values[i] = value
+		}
+	case reflect.Slice:
+		if rv.Len() != len(idFields) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidId, id)
+		}
+		for i := range values {
+			values[i] = rv.Index(i).Interface()
+		}
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidId, id)
+	}
+	return values, nil
+}
+
+// SetCompositeId sets the composite id to the id fields of
+// the entity pointed by self, which implements Entity.SetId
+// for the entity with a composite key.
+func SetCompositeId(self any, id any) error {
+	rv := reflect.ValueOf(self).Elem()
+	idFields := FindIdFields(rv.Type())
+	values, err := ReadIdValues(id, idFields)
+	if err != nil {
+		return err
+	}
+	for i, md := range idFields {
+		if !setFieldValue(rv.FieldByName(md.Field.Name), values[i]) {
+			return fmt.Errorf("%w: %v", ErrInvalidId, id)
+		}
+	}
+	return nil
+}
+
+func parseValue(s string, fieldType reflect.Type) (any, error) {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	v := reflect.New(fieldType).Elem()
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		v.SetUint(n)
+	case reflect.String:
+		v.SetString(s)
+	default:
+		return nil, fmt.Errorf("unsupported id type: %s", fieldType)
+	}
+	return v.Interface(), nil
+}
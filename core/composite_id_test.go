@@ -0,0 +1,68 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type userRoleKey struct {
+	UserId int
+	RoleId string
+}
+
+type userRoleEntity struct {
+	UserId *int64  `id:""`
+	RoleId *string `id:""`
+	Memo   *string
+}
+
+func (e userRoleEntity) GetId() any {
+	return userRoleKey{int(*e.UserId), *e.RoleId}
+}
+
+func (e userRoleEntity) SetId(self any, id any) error {
+	return SetCompositeId(self, id)
+}
+
+func TestSetCompositeId(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+	}{
+		{"Support key struct", userRoleKey{1, "admin"}},
+		{"Support pointer to key struct", &userRoleKey{1, "admin"}},
+		{"Support string joined by commas", "1,admin"},
+		{"Support slice", []any{1, "admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entity := userRoleEntity{}
+			if err := entity.SetId(&entity, tt.input); err != nil {
+				t.Fatal(err)
+			}
+			if got := entity.GetId(); !reflect.DeepEqual(got, userRoleKey{1, "admin"}) {
+				t.Errorf("GetId() = %v, want %v", got, userRoleKey{1, "admin"})
+			}
+		})
+	}
+}
+
+func TestSetCompositeIdWithInvalidId(t *testing.T) {
+	for _, id := range []any{nil, 1, "1", "a,admin", []any{1}, []any{"1", "admin"}, struct{ UserId int }{1}} {
+		entity := userRoleEntity{}
+		if err := entity.SetId(&entity, id); !errors.Is(err, ErrInvalidId) {
+			t.Errorf("%v: Expected %v, but got %v", id, ErrInvalidId, err)
+		}
+	}
+}
@@ -12,6 +12,7 @@ package core
 
 import (
 	"fmt"
+	"sync"
 )
 
 var Config = struct {
@@ -24,7 +25,14 @@ var Config = struct {
 	"a_%s_and_%s",
 }
 
-var m = map[string]string{}
+// m holds the registered join tables and virtual entities.
+var m sync.Map
+
+func lookup(key string) string {
+	value, _ := m.Load(key)
+	s, _ := value.(string)
+	return s
+}
 
 func FormatTable(domain string) string {
 	return fmt.Sprintf(Config.TableFormat, domain)
@@ -35,22 +43,22 @@ func FormatJoinId(domain string) string {
 }
 
 func FormatJoinTable(domain1 string, domain2 string) string {
-	if table := m[domain1+"_"+domain2]; table != "" {
+	if table := lookup(domain1 + "_" + domain2); table != "" {
 		return table
 	}
 	return fmt.Sprintf(Config.JoinTableFormat, domain1, domain2)
 }
 
 func RegisterJoinTable(domain1 string, domain2 string, table string) {
-	m[domain1+"_"+domain2] = table
+	m.Store(domain1+"_"+domain2, table)
 }
 
 func RegisterVirtualEntity(ve string, target string) {
-	m[ve] = target
+	m.Store(ve, target)
 }
 
 func MapVirtualEntity(ve string) string {
-	if target := m[ve]; target != "" {
+	if target := lookup(ve); target != "" {
 		return target
 	}
 	return ve
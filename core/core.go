@@ -25,6 +25,13 @@ type PageList[D any] struct {
 	Total int64 `json:"total"`
 }
 
+// CursorPage is the result of the keyset pagination,
+// where Next is the cursor to fetch the next page.
+type CursorPage[D any] struct {
+	List []D    `json:"list"`
+	Next string `json:"next,omitempty"`
+}
+
 type Response struct {
 	Data    any     `json:"data,omitempty"`
 	Success bool    `json:"success"`
@@ -55,17 +62,36 @@ type DataAccess[E Entity] interface {
 	Count(ctx context.Context, query Query) (int64, error)
 	DeleteByQuery(ctx context.Context, query Query) (int64, error)
 	Page(ctx context.Context, query Query) (PageList[E], error)
+	CursorPage(ctx context.Context, query Query) (CursorPage[E], error)
+
+	// Iterate calls fn with the entities matching query one at a time,
+	// and stops at the first error returned by fn or the cancellation
+	// of ctx, which is returned as the result.
+	Iterate(ctx context.Context, query Query, fn func(entity E) error) error
 	Create(ctx context.Context, entity *E) (int64, error)
 	CreateMulti(ctx context.Context, entities []E) (int64, error)
 	Update(ctx context.Context, entity E) (int64, error)
 	Patch(ctx context.Context, entity Entity) (int64, error)
 	PatchByQuery(ctx context.Context, entity E, query Query) (int64, error)
+
+	// Upsert inserts the entity, or updates the existing record
+	// conflicting with it on the id or the ConflictColumns.
+	Upsert(ctx context.Context, entity E) (int64, error)
+	UpsertMulti(ctx context.Context, entities []E) (int64, error)
+}
+
+// UpsertEntity is implemented by the entities which are
+// upserted on the unique columns instead of the id.
+type UpsertEntity interface {
+	ConflictColumns() []string
 }
 
+// TransactionManager starts the transactions by the TxOptions,
+// which joins the transaction in ctx by default.
 type TransactionManager interface {
 	GetClient() any
-	StartTransaction(ctx context.Context) (TransactionContext, error)
-	SubmitTransaction(ctx context.Context, callback func(tc TransactionContext) error) error
+	StartTransaction(ctx context.Context, opts ...TxOption) (TransactionContext, error)
+	SubmitTransaction(ctx context.Context, callback func(tc TransactionContext) error, opts ...TxOption) error
 }
 
 type TransactionContext interface {
@@ -0,0 +1,178 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorQuery is implemented by the queries supporting the keyset
+// pagination, which fetches the records after the cursor of the
+// last record of the previous page instead of skipping an offset.
+type CursorQuery interface {
+	Query
+
+	// GetAfter returns nil for the offset pagination, an empty
+	// string for the first page or the cursor from CursorPage.Next.
+	GetAfter() *string
+}
+
+// ReadAfter returns the cursor of query and whether
+// the query should be paged by the cursor.
+func ReadAfter(query Query) (string, bool) {
+	if cq, ok := query.(CursorQuery); ok && cq.GetAfter() != nil {
+		return *cq.GetAfter(), true
+	}
+	return "", false
+}
+
+type SortColumn struct {
+	Name string
+	Desc bool
+}
+
+// BuildCursorColumns resolves the sort columns of the keyset
+// pagination, which ends with the id columns as the tie-breaker.
+func BuildCursorColumns(sort string, idColumns ...string) []SortColumn {
+	groups := SortRgx.FindAllStringSubmatch(sort, -1)
+	columns := make([]SortColumn, 0, len(groups)+len(idColumns))
+	for _, group := range groups {
+		columns = append(columns, SortColumn{group[1], strings.EqualFold(group[3], "desc")})
+		if len(idColumns) == 1 && group[1] == idColumns[0] {
+			return columns
+		}
+	}
+	for _, idColumn := range idColumns {
+		if !containsSortColumn(columns, idColumn) {
+			columns = append(columns, SortColumn{Name: idColumn})
+		}
+	}
+	return columns
+}
+
+func containsSortColumn(columns []SortColumn, name string) bool {
+	for _, column := range columns {
+		if column.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+type cursorCodec struct {
+	encode func(value any) (string, bool)
+	decode func(text string) (any, error)
+}
+
+// cursorCodecs holds the codecs of the cursor values by the type
+// tags, which restore the values of the types not kept by JSON.
+var cursorCodecs sync.Map
+
+// RegisterCursorType registers the codec of the cursor values tagged
+// by tag, where encode formats the value and reports whether the value
+// is of the type, and decode parses the formatted text back.
+func RegisterCursorType(tag string, encode func(value any) (string, bool), decode func(text string) (any, error)) {
+	cursorCodecs.Store(tag, cursorCodec{encode: encode, decode: decode})
+}
+
+func init() {
+	RegisterCursorType("time", func(value any) (string, bool) {
+		t, ok := value.(time.Time)
+		return t.Format(time.RFC3339Nano), ok
+	}, func(text string) (any, error) {
+		return time.Parse(time.RFC3339Nano, text)
+	})
+}
+
+// taggedValue is the cursor value of a registered type.
+type taggedValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v"`
+}
+
+func tagCursorValue(value any) any {
+	tagged := value
+	cursorCodecs.Range(func(tag, codec any) bool {
+		text, ok := codec.(cursorCodec).encode(value)
+		if ok {
+			tagged = taggedValue{Type: tag.(string), Value: text}
+		}
+		return !ok
+	})
+	return tagged
+}
+
+func untagCursorValue(value map[string]any) (any, error) {
+	tag, _ := value["t"].(string)
+	text, ok := value["v"].(string)
+	codec, found := cursorCodecs.Load(tag)
+	if !ok || !found {
+		return nil, ErrInvalidCursor
+	}
+	decoded, err := codec.(cursorCodec).decode(text)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return decoded, nil
+}
+
+// EncodeCursor encodes the values of the cursor columns
+// of the last record into an opaque cursor, where the
+// values of the registered types are tagged by the types.
+func EncodeCursor(values []any) (string, error) {
+	tagged := make([]any, len(values))
+	for i, value := range values {
+		tagged[i] = tagCursorValue(value)
+	}
+	data, err := json.Marshal(tagged)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes the values of the cursor columns,
+// where the numbers are decoded as int64 or float64, and
+// the tagged values are decoded as the registered types.
+func DecodeCursor(cursor string, size int) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var values []any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if decoder.Decode(&values) != nil || len(values) != size {
+		return nil, ErrInvalidCursor
+	}
+	for i, value := range values {
+		switch v := value.(type) {
+		case json.Number:
+			if values[i], err = v.Int64(); err != nil {
+				values[i], err = v.Float64()
+			}
+		case map[string]any:
+			values[i], err = untagCursorValue(v)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
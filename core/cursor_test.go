@@ -0,0 +1,51 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("Restore the values of the registered types", func(t *testing.T) {
+		created := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+		cursor, err := EncodeCursor([]any{created, "f0rb", 5, 1.5, nil})
+		if err != nil {
+			t.Fatal(err)
+		}
+		values, err := DecodeCursor(cursor, 5)
+		expect := []any{created, "f0rb", int64(5), 1.5, nil}
+		if err != nil || !reflect.DeepEqual(values, expect) {
+			t.Errorf("DecodeCursor() = %v, %v, want %v", values, err, expect)
+		}
+	})
+
+	t.Run("Reject the invalid cursors", func(t *testing.T) {
+		encode := func(json string) string { return base64.RawURLEncoding.EncodeToString([]byte(json)) }
+		tests := []struct {
+			name   string
+			cursor string
+		}{
+			{"Not base64", "!!"},
+			{"Mismatched size", encode(`[1]`)},
+			{"Unknown type", encode(`[{"t":"unknown","v":"1"},2]`)},
+			{"Malformed value", encode(`[{"t":"time","v":"yesterday"},2]`)},
+		}
+		for _, tt := range tests {
+			if _, err := DecodeCursor(tt.cursor, 2); err != ErrInvalidCursor {
+				t.Errorf("%s: DecodeCursor() error = %v, want %v", tt.name, err, ErrInvalidCursor)
+			}
+		}
+	})
+}
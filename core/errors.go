@@ -0,0 +1,46 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import "errors"
+
+// ErrNotFound is returned by Get when no record matches the id.
+var ErrNotFound = errors.New("record not found")
+
+// ErrNoCondition is returned when an update or a deletion by
+// a query has no condition, which would affect all records.
+var ErrNoCondition = errors.New("deletion of all records is restricted")
+
+// ErrNoFieldToUpdate is returned when a patch has no field to update.
+var ErrNoFieldToUpdate = errors.New("at least one field should be updated")
+
+// ErrConstraintViolation is matched by the errors of the drivers
+// on the violation of a constraint, such as a duplicate key.
+var ErrConstraintViolation = errors.New("constraint violation")
+
+// ConstraintError wraps the error of a driver on the violation of a
+// constraint, which is matched by ErrConstraintViolation with errors.Is
+// and keeps the error of the driver available by errors.As.
+type ConstraintError struct {
+	Err error
+}
+
+func (e *ConstraintError) Error() string {
+	return ErrConstraintViolation.Error() + ": " + e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ConstraintError) Is(target error) bool {
+	return target == ErrConstraintViolation
+}
@@ -13,6 +13,7 @@ package core
 import (
 	"reflect"
 	"strings"
+	"sync"
 )
 
 type FieldMetadata struct {
@@ -22,28 +23,58 @@ type FieldMetadata struct {
 	EntityPath *EntityPath
 }
 
-var typeFmMap = make(map[reflect.Type][]FieldMetadata)
+// typeFmMap caches the []FieldMetadata by the struct type.
+var typeFmMap sync.Map
 
+// BuildFieldMetas builds the metadata of the fields of structType,
+// skipping the fields tagged by `column:"-"`. The fields tagged by
+// `id` are the id of the struct, otherwise the field named Id is.
 func BuildFieldMetas(structType reflect.Type) []FieldMetadata {
-	fieldMetas := typeFmMap[structType]
-	if fieldMetas == nil {
-		fieldMetas = make([]FieldMetadata, 0, structType.NumField())
-		for i := 0; i < structType.NumField(); i++ {
-			fieldMetas = append(fieldMetas, buildFieldMetadata(structType.Field(i))...)
+	if fieldMetas, ok := typeFmMap.Load(structType); ok {
+		return fieldMetas.([]FieldMetadata)
+	}
+	fieldMetas := make([]FieldMetadata, 0, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		fieldMetas = append(fieldMetas, buildFieldMetadata(structType.Field(i))...)
+	}
+	markIdTagged(fieldMetas)
+	actual, _ := typeFmMap.LoadOrStore(structType, fieldMetas)
+	return actual.([]FieldMetadata)
+}
+
+func markIdTagged(fieldMetas []FieldMetadata) {
+	for _, fm := range fieldMetas {
+		if _, ok := fm.Field.Tag.Lookup("id"); ok {
+			for i := range fieldMetas {
+				_, fieldMetas[i].IsId = fieldMetas[i].Field.Tag.Lookup("id")
+			}
+			return
 		}
-		typeFmMap[structType] = fieldMetas
 	}
-	return fieldMetas
+}
+
+// ColumnTag returns the column named by the `column` tag, which
+// is "-" for an ignored field, or an empty string if absent.
+// The options after the name, such as `,index`, are dropped.
+func ColumnTag(tag reflect.StructTag) string {
+	column, _, _ := strings.Cut(tag.Get("column"), ",")
+	return column
 }
 
 func buildFieldMetadata(field reflect.StructField) []FieldMetadata {
-	if field.Type.Kind() == reflect.Struct {
+	column := ColumnTag(field.Tag)
+	if column == "-" {
+		return nil
+	} else if field.Type.Kind() == reflect.Struct {
 		return BuildFieldMetas(field.Type)
+	} else if column == "" {
+		column = ConvertToColumnCase(field.Name)
 	}
+	_, isId := field.Tag.Lookup("id")
 	cm := FieldMetadata{
 		Field:      field,
-		IsId:       field.Name == "Id",
-		ColumnName: ConvertToColumnCase(field.Name),
+		IsId:       isId || field.Name == "Id",
+		ColumnName: column,
 	}
 	if _, ok := field.Tag.Lookup("entitypath"); ok {
 		cm.EntityPath = BuildEntityPath(field)
@@ -26,6 +26,13 @@ type UserEntity struct {
 	Friends []UserEntity `entitypath:""user,friend,friend,friend"" json:"roles,omitempty"`
 }
 
+type LegacyUserEntity struct {
+	UserNo   *int64  `id:"" column:"user_no"`
+	Username *string `column:"usr_nm,index"`
+	Password *string `column:"-"`
+	Memo     *string
+}
+
 type RoleEntity struct {
 	IntId
 	RoleName     *string
@@ -50,4 +57,31 @@ func TestBuildFieldMetas(t *testing.T) {
 			t.Errorf("\nExpected: %d\n     Got: %d", expect, actual)
 		}
 	})
+
+	t.Run("Build FieldMetadata by tags", func(t *testing.T) {
+		fieldMetas := BuildFieldMetas(reflect.TypeOf(LegacyUserEntity{}))
+		actual := make([]string, len(fieldMetas))
+		for i, fm := range fieldMetas {
+			actual[i] = fm.Field.Name + ":" + fm.ColumnName
+			if fm.IsId != (fm.Field.Name == "UserNo") {
+				t.Errorf("Unexpected IsId %v of %s", fm.IsId, fm.Field.Name)
+			}
+		}
+		expect := []string{"UserNo:user_no", "Username:usr_nm", "Memo:memo"}
+		if !reflect.DeepEqual(actual, expect) {
+			t.Errorf("\nExpected: %v\n     Got: %v", expect, actual)
+		}
+	})
+
+	t.Run("Tag id overrides the field named Id", func(t *testing.T) {
+		type CodeEntity struct {
+			IntId
+			Code *string `id:""`
+		}
+		for _, fm := range BuildFieldMetas(reflect.TypeOf(CodeEntity{})) {
+			if fm.IsId != (fm.Field.Name == "Code") {
+				t.Errorf("Unexpected IsId %v of %s", fm.IsId, fm.Field.Name)
+			}
+		}
+	})
 }
@@ -0,0 +1,103 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import "context"
+
+// The hooks are implemented by the pointers of the entities and
+// invoked by the DataAccess with the ctx of the caller, which is
+// the TransactionContext when the operation runs in a transaction.
+// An error returned by a BeforeXxx hook aborts the operation.
+//
+// The delete hooks receive an entity with only the id set.
+// DeleteByQuery invokes no hook since no entity is loaded.
+
+type BeforeCreateHook interface {
+	BeforeCreate(ctx context.Context) error
+}
+
+type AfterCreateHook interface {
+	AfterCreate(ctx context.Context) error
+}
+
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+type AfterDeleteHook interface {
+	AfterDelete(ctx context.Context) error
+}
+
+func BeforeCreate(ctx context.Context, self any) error {
+	if hook, ok := self.(BeforeCreateHook); ok {
+		return hook.BeforeCreate(ctx)
+	}
+	return nil
+}
+
+func AfterCreate(ctx context.Context, self any) error {
+	if hook, ok := self.(AfterCreateHook); ok {
+		return hook.AfterCreate(ctx)
+	}
+	return nil
+}
+
+func BeforeUpdate(ctx context.Context, self any) error {
+	if hook, ok := self.(BeforeUpdateHook); ok {
+		return hook.BeforeUpdate(ctx)
+	}
+	return nil
+}
+
+func AfterUpdate(ctx context.Context, self any) error {
+	if hook, ok := self.(AfterUpdateHook); ok {
+		return hook.AfterUpdate(ctx)
+	}
+	return nil
+}
+
+func BeforeDelete(ctx context.Context, self any) error {
+	if hook, ok := self.(BeforeDeleteHook); ok {
+		return hook.BeforeDelete(ctx)
+	}
+	return nil
+}
+
+func AfterDelete(ctx context.Context, self any) error {
+	if hook, ok := self.(AfterDeleteHook); ok {
+		return hook.AfterDelete(ctx)
+	}
+	return nil
+}
+
+// NewDeleteHookEntity builds the entity with the id and invokes
+// BeforeDelete on it when the entity implements the delete hooks,
+// or returns nil otherwise. The entity is passed to AfterDelete.
+func NewDeleteHookEntity[E Entity](ctx context.Context, id any) (*E, error) {
+	self := new(E)
+	_, before := any(self).(BeforeDeleteHook)
+	_, after := any(self).(AfterDeleteHook)
+	if !before && !after {
+		return nil, nil
+	}
+	if err := (*self).SetId(self, id); err != nil {
+		return nil, err
+	}
+	return self, BeforeDelete(ctx, self)
+}
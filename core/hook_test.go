@@ -0,0 +1,50 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type deleteHookEntity struct {
+	IntId
+}
+
+func (e *deleteHookEntity) BeforeDelete(ctx context.Context) error {
+	if e.Id == 1 {
+		return errors.New("undeletable")
+	}
+	return nil
+}
+
+func TestNewDeleteHookEntity(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Skip the entity without hooks", func(t *testing.T) {
+		if self, err := NewDeleteHookEntity[IntId](ctx, 1); self != nil || err != nil {
+			t.Errorf("NewDeleteHookEntity() = %v, %v", self, err)
+		}
+	})
+
+	t.Run("Build the entity with the id", func(t *testing.T) {
+		if self, err := NewDeleteHookEntity[deleteHookEntity](ctx, "2"); err != nil || self.Id != 2 {
+			t.Errorf("NewDeleteHookEntity() = %v, %v", self, err)
+		}
+	})
+
+	t.Run("Abort by the error of BeforeDelete", func(t *testing.T) {
+		if _, err := NewDeleteHookEntity[deleteHookEntity](ctx, 1); err == nil || err.Error() != "undeletable" {
+			t.Errorf("NewDeleteHookEntity() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,152 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"crypto/rand"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// IdGenerator generates the ids of the entities before the insert.
+type IdGenerator interface {
+	NextId() (any, error)
+}
+
+// IdGeneratorFunc adapts a function to IdGenerator.
+type IdGeneratorFunc func() (any, error)
+
+func (f IdGeneratorFunc) NextId() (any, error) {
+	return f()
+}
+
+// IdGeneratorProvider is implemented by the id types
+// which are generated by the client by default, e.g. UUIDId.
+type IdGeneratorProvider interface {
+	IdGenerator() IdGenerator
+}
+
+// UUIDv4 generates the random UUIDs of version 4.
+var UUIDv4 IdGenerator = IdGeneratorFunc(func() (any, error) { return NewUUIDv4() })
+
+// UUIDv7 generates the time-ordered UUIDs of version 7,
+// which keep the index of the primary key compact.
+var UUIDv7 IdGenerator = IdGeneratorFunc(func() (any, error) { return NewUUIDv7() })
+
+// idGeneratorMap holds the IdGenerator by the entity type.
+var idGeneratorMap sync.Map
+
+// RegisterIdGenerator registers generator for the entity type E,
+// which overrides the default one of the id type.
+func RegisterIdGenerator[E Entity](generator IdGenerator) {
+	idGeneratorMap.Store(reflect.TypeOf(*new(E)), generator)
+}
+
+// ResolveIdGenerator returns the IdGenerator registered for the
+// entity type or provided by its id type, or nil when the ids are
+// generated by the database.
+func ResolveIdGenerator(entityType reflect.Type) IdGenerator {
+	if generator, ok := idGeneratorMap.Load(entityType); ok {
+		return generator.(IdGenerator)
+	}
+	if provider, ok := reflect.Zero(entityType).Interface().(IdGeneratorProvider); ok {
+		return provider.IdGenerator()
+	}
+	return nil
+}
+
+// GenerateId sets the id generated by generator to the entity
+// pointed by self, unless the id of the entity is assigned.
+func GenerateId[E Entity](self *E, generator IdGenerator) error {
+	if generator == nil || !isZeroId((*self).GetId()) {
+		return nil
+	}
+	id, err := generator.NextId()
+	if err != nil {
+		return err
+	}
+	return (*self).SetId(self, id)
+}
+
+func isZeroId(id any) bool {
+	return id == nil || reflect.ValueOf(id).IsZero()
+}
+
+// NewUUIDv4 returns a random UUID of version 4.
+func NewUUIDv4() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
+
+// NewUUIDv7 returns a UUID of version 7, which starts
+// with the Unix milliseconds of Now followed by random bits.
+func NewUUIDv7() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+	ms := uint64(Now().UnixMilli())
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
+
+func formatUUID(b [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// SnowflakeEpoch is the epoch of the timestamps of Snowflake ids.
+var SnowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake generates the int64 ids composed of 41 bits of the
+// milliseconds since SnowflakeEpoch, 10 bits of the node and 12
+// bits of the sequence within the millisecond.
+type Snowflake struct {
+	mu       sync.Mutex
+	node     int64
+	lastMs   int64
+	sequence int64
+}
+
+// NewSnowflake returns the Snowflake for node in [0, 1023].
+func NewSnowflake(node int64) *Snowflake {
+	return &Snowflake{node: node & 0x3ff}
+}
+
+// NextId returns the next id, which keeps increasing even if
+// the clock moves back or the sequence of a millisecond runs
+// out, by borrowing the following milliseconds.
+func (s *Snowflake) NextId() (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ms := Now().Sub(SnowflakeEpoch).Milliseconds()
+	if ms <= s.lastMs {
+		s.sequence = (s.sequence + 1) & 0xfff
+		ms = s.lastMs
+		if s.sequence == 0 {
+			ms++
+		}
+	} else {
+		s.sequence = 0
+	}
+	s.lastMs = ms
+	return ms<<22 | s.node<<12 | s.sequence, nil
+}
@@ -0,0 +1,113 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type deviceEntity struct {
+	UUIDId
+	Name *string
+}
+
+type tokenEntity struct {
+	StringId
+}
+
+func TestUUID(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	defer func(clock func() time.Time) { Now = clock }(Now)
+	Now = func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		generator IdGenerator
+		rgx       *regexp.Regexp
+	}{
+		{"Generate UUIDv4", UUIDv4, regexp.MustCompile(`^[\da-f]{8}-[\da-f]{4}-4[\da-f]{3}-[89ab][\da-f]{3}-[\da-f]{12}$`)},
+		{"Generate UUIDv7 with the milliseconds", UUIDv7, regexp.MustCompile(`^019b7ca9-8c88-7[\da-f]{3}-[89ab][\da-f]{3}-[\da-f]{12}$`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id1, _ := tt.generator.NextId()
+			id2, _ := tt.generator.NextId()
+			if !tt.rgx.MatchString(id1.(string)) || id1 == id2 {
+				t.Errorf("Unexpected UUIDs: %s, %s", id1, id2)
+			}
+		})
+	}
+}
+
+func TestSnowflake(t *testing.T) {
+	now := SnowflakeEpoch.Add(time.Second)
+	defer func(clock func() time.Time) { Now = clock }(Now)
+	Now = func() time.Time { return now }
+
+	snowflake := NewSnowflake(3)
+	t.Run("Compose the milliseconds, the node and the sequence", func(t *testing.T) {
+		id1, _ := snowflake.NextId()
+		id2, _ := snowflake.NextId()
+		expect := []any{int64(1000<<22 | 3<<12), int64(1000<<22 | 3<<12 | 1)}
+		if actual := []any{id1, id2}; !reflect.DeepEqual(actual, expect) {
+			t.Errorf("\nExpected: %v\n     Got: %v", expect, actual)
+		}
+	})
+
+	t.Run("Keep increasing when the clock moves back", func(t *testing.T) {
+		last, _ := snowflake.NextId()
+		now = now.Add(-time.Second)
+		for i := 0; i < 5000; i++ {
+			id, _ := snowflake.NextId()
+			if id.(int64) <= last.(int64) {
+				t.Fatalf("%d is not greater than %d", id, last)
+			}
+			last = id
+		}
+	})
+}
+
+func TestGenerateId(t *testing.T) {
+	t.Run("Generate UUID for UUIDId", func(t *testing.T) {
+		entity := deviceEntity{}
+		if err := GenerateId(&entity, ResolveIdGenerator(reflect.TypeOf(entity))); err != nil {
+			t.Fatal(err)
+		}
+		if len(entity.Id) != 36 {
+			t.Errorf("Unexpected id: %s", entity.Id)
+		}
+	})
+
+	t.Run("Keep the assigned id", func(t *testing.T) {
+		entity := deviceEntity{UUIDId: NewUUIDId("assigned")}
+		_ = GenerateId(&entity, ResolveIdGenerator(reflect.TypeOf(entity)))
+		if entity.Id != "assigned" {
+			t.Errorf("Unexpected id: %s", entity.Id)
+		}
+	})
+
+	t.Run("Generate by the registered IdGenerator", func(t *testing.T) {
+		if ResolveIdGenerator(reflect.TypeOf(tokenEntity{})) != nil {
+			t.Fatal("StringId should not be generated by default")
+		}
+		RegisterIdGenerator[tokenEntity](IdGeneratorFunc(func() (any, error) { return "t-1", nil }))
+		defer idGeneratorMap.Delete(reflect.TypeOf(tokenEntity{}))
+
+		entity := tokenEntity{}
+		_ = GenerateId(&entity, ResolveIdGenerator(reflect.TypeOf(entity)))
+		if entity.Id != "t-1" {
+			t.Errorf("Unexpected id: %s", entity.Id)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Invocation describes a call to the DataAccess, where Operation
+// is the name of the method and Args are the arguments after ctx,
+// such as the id, the query, the entity or the entities.
+type Invocation struct {
+	Operation string
+	Args      []any
+}
+
+// Interceptor intercepts an invocation of the DataAccess. It calls
+// next to proceed to the next interceptor or the DataAccess, and
+// returns the result, which could be replaced or short-circuited.
+type Interceptor func(ctx context.Context, inv *Invocation, next func(ctx context.Context) (any, error)) (any, error)
+
+type interceptedDataAccess[E Entity] struct {
+	DataAccess[E]
+	interceptors []Interceptor
+}
+
+// Wrap decorates da by the interceptors, where the
+// first interceptor is the outermost one.
+func Wrap[E Entity](da DataAccess[E], interceptors ...Interceptor) DataAccess[E] {
+	if len(interceptors) == 0 {
+		return da
+	}
+	return &interceptedDataAccess[E]{DataAccess: da, interceptors: interceptors}
+}
+
+// WrapTx decorates the DataAccess of tda by the interceptors.
+func WrapTx[E Entity](tda TxDataAccess[E], interceptors ...Interceptor) TxDataAccess[E] {
+	return TxDataAccess[E]{
+		TransactionManager: tda.TransactionManager,
+		DataAccess:         Wrap(tda.DataAccess, interceptors...),
+	}
+}
+
+func (d *interceptedDataAccess[E]) invoke(ctx context.Context, operation string, args []any, call func(ctx context.Context) (any, error)) (any, error) {
+	inv := &Invocation{Operation: operation, Args: args}
+	chain := call
+	for i := len(d.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := d.interceptors[i], chain
+		chain = func(ctx context.Context) (any, error) {
+			return interceptor(ctx, inv, next)
+		}
+	}
+	return chain(ctx)
+}
+
+func as[T any](result any) T {
+	t, _ := result.(T)
+	return t
+}
+
+func (d *interceptedDataAccess[E]) Get(ctx context.Context, id any) (*E, error) {
+	result, err := d.invoke(ctx, "Get", []any{id}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.Get(ctx, id)
+	})
+	return as[*E](result), err
+}
+
+func (d *interceptedDataAccess[E]) Delete(ctx context.Context, id any) (int64, error) {
+	result, err := d.invoke(ctx, "Delete", []any{id}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.Delete(ctx, id)
+	})
+	return as[int64](result), err
+}
+
+func (d *interceptedDataAccess[E]) Query(ctx context.Context, query Query) ([]E, error) {
+	result, err := d.invoke(ctx, "Query", []any{query}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.Query(ctx, query)
+	})
+	return as[[]E](result), err
+}
+
+func (d *interceptedDataAccess[E]) Count(ctx context.Context, query Query) (int64, error) {
+	result, err := d.invoke(ctx, "Count", []any{query}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.Count(ctx, query)
+	})
+	return as[int64](result), err
+}
+
+func (d *interceptedDataAccess[E]) DeleteByQuery(ctx context.Context, query Query) (int64, error) {
+	result, err := d.invoke(ctx, "DeleteByQuery", []any{query}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.DeleteByQuery(ctx, query)
+	})
+	return as[int64](result), err
+}
+
+func (d *interceptedDataAccess[E]) Page(ctx context.Context, query Query) (PageList[E], error) {
+	result, err := d.invoke(ctx, "Page", []any{query}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.Page(ctx, query)
+	})
+	return as[PageList[E]](result), err
+}
+
+func (d *interceptedDataAccess[E]) CursorPage(ctx context.Context, query Query) (CursorPage[E], error) {
+	result, err := d.invoke(ctx, "CursorPage", []any{query}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.CursorPage(ctx, query)
+	})
+	return as[CursorPage[E]](result), err
+}
+
+func (d *interceptedDataAccess[E]) Iterate(ctx context.Context, query Query, fn func(entity E) error) error {
+	_, err := d.invoke(ctx, "Iterate", []any{query}, func(ctx context.Context) (any, error) {
+		return nil, d.DataAccess.Iterate(ctx, query, fn)
+	})
+	return err
+}
+
+func (d *interceptedDataAccess[E]) Create(ctx context.Context, entity *E) (int64, error) {
+	result, err := d.invoke(ctx, "Create", []any{entity}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.Create(ctx, entity)
+	})
+	return as[int64](result), err
+}
+
+func (d *interceptedDataAccess[E]) CreateMulti(ctx context.Context, entities []E) (int64, error) {
+	result, err := d.invoke(ctx, "CreateMulti", []any{entities}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.CreateMulti(ctx, entities)
+	})
+	return as[int64](result), err
+}
+
+func (d *interceptedDataAccess[E]) Update(ctx context.Context, entity E) (int64, error) {
+	result, err := d.invoke(ctx, "Update", []any{entity}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.Update(ctx, entity)
+	})
+	return as[int64](result), err
+}
+
+func (d *interceptedDataAccess[E]) Patch(ctx context.Context, entity Entity) (int64, error) {
+	result, err := d.invoke(ctx, "Patch", []any{entity}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.Patch(ctx, entity)
+	})
+	return as[int64](result), err
+}
+
+func (d *interceptedDataAccess[E]) PatchByQuery(ctx context.Context, entity E, query Query) (int64, error) {
+	result, err := d.invoke(ctx, "PatchByQuery", []any{entity, query}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.PatchByQuery(ctx, entity, query)
+	})
+	return as[int64](result), err
+}
+
+func (d *interceptedDataAccess[E]) Upsert(ctx context.Context, entity E) (int64, error) {
+	result, err := d.invoke(ctx, "Upsert", []any{entity}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.Upsert(ctx, entity)
+	})
+	return as[int64](result), err
+}
+
+func (d *interceptedDataAccess[E]) UpsertMulti(ctx context.Context, entities []E) (int64, error) {
+	result, err := d.invoke(ctx, "UpsertMulti", []any{entities}, func(ctx context.Context) (any, error) {
+		return d.DataAccess.UpsertMulti(ctx, entities)
+	})
+	return as[int64](result), err
+}
+
+// LoggingInterceptor logs the invocations with the results at
+// the debug level, and the failed ones at the warning level.
+func LoggingInterceptor(ctx context.Context, inv *Invocation, next func(ctx context.Context) (any, error)) (any, error) {
+	result, err := next(ctx)
+	if err != nil {
+		log.Warnf("%s%v failed: %v", inv.Operation, inv.Args, err)
+	} else {
+		log.Debugf("%s%v returned: %v", inv.Operation, inv.Args, result)
+	}
+	return result, err
+}
+
+// TimingInterceptor reports the elapsed time of each invocation to observe.
+func TimingInterceptor(observe func(inv *Invocation, elapsed time.Duration)) Interceptor {
+	return func(ctx context.Context, inv *Invocation, next func(ctx context.Context) (any, error)) (any, error) {
+		start := time.Now()
+		result, err := next(ctx)
+		observe(inv, time.Since(start))
+		return result, err
+	}
+}
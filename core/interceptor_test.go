@@ -0,0 +1,89 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stubDataAccess struct {
+	DataAccess[IntId]
+}
+
+func (s *stubDataAccess) Get(_ context.Context, id any) (*IntId, error) {
+	if id == 0 {
+		return nil, errors.New("not found")
+	}
+	return &IntId{Id: id.(int)}, nil
+}
+
+func (s *stubDataAccess) Count(_ context.Context, _ Query) (int64, error) {
+	return 5, nil
+}
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+	var events []string
+	recorder := func(name string) Interceptor {
+		return func(ctx context.Context, inv *Invocation, next func(ctx context.Context) (any, error)) (any, error) {
+			events = append(events, fmt.Sprintf("%s before %s%v", name, inv.Operation, inv.Args))
+			result, err := next(ctx)
+			events = append(events, fmt.Sprintf("%s after %v %v", name, result, err))
+			return result, err
+		}
+	}
+
+	t.Run("Invoke the interceptors in order", func(t *testing.T) {
+		events = nil
+		da := Wrap[IntId](&stubDataAccess{}, recorder("outer"), recorder("inner"), LoggingInterceptor)
+		cnt, err := da.Count(ctx, PageQuery{})
+
+		expect := []string{
+			"outer before Count[{0 0  <nil>}]", "inner before Count[{0 0  <nil>}]",
+			"inner after 5 <nil>", "outer after 5 <nil>",
+		}
+		if cnt != 5 || err != nil || !reflect.DeepEqual(events, expect) {
+			t.Errorf("\nExpected: %v\nBut got : %v", expect, events)
+		}
+	})
+
+	t.Run("Short-circuit by the interceptor", func(t *testing.T) {
+		guard := func(ctx context.Context, inv *Invocation, next func(ctx context.Context) (any, error)) (any, error) {
+			if inv.Args[0] == 0 {
+				return &IntId{Id: -1}, nil
+			}
+			return next(ctx)
+		}
+		da := WrapTx(TxDataAccess[IntId]{DataAccess: &stubDataAccess{}}, guard)
+		if e, err := da.Get(ctx, 0); err != nil || e.Id != -1 {
+			t.Errorf("Get() = %v, %v", e, err)
+		}
+		if e, err := da.Get(ctx, 3); err != nil || e.Id != 3 {
+			t.Errorf("Get() = %v, %v", e, err)
+		}
+	})
+
+	t.Run("Report the elapsed time", func(t *testing.T) {
+		var operation string
+		timing := TimingInterceptor(func(inv *Invocation, elapsed time.Duration) {
+			operation = fmt.Sprintf("%s %t", inv.Operation, elapsed >= 0)
+		})
+		_, err := Wrap[IntId](&stubDataAccess{}, timing).Get(ctx, 0)
+		if err == nil || operation != "Get true" {
+			t.Errorf("Get() = %v, %s", err, operation)
+		}
+	})
+}
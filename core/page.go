@@ -10,10 +10,20 @@
 
 package core
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var ErrInvalidSort = errors.New("invalid sort column")
+
 type PageQuery struct {
-	Page int    `json:"page,omitempty"`
-	Size int    `json:"size,omitempty"`
-	Sort string `json:"sort,omitempty"`
+	Page  int     `json:"page,omitempty"`
+	Size  int     `json:"size,omitempty"`
+	Sort  string  `json:"sort,omitempty"`
+	After *string `json:"after,omitempty"`
 }
 
 func (pq PageQuery) GetPageNumber() int {
@@ -35,3 +45,47 @@ func (pq PageQuery) GetSort() string {
 func (pq PageQuery) NeedPaging() bool {
 	return pq.Size > 0 || pq.Page > 0
 }
+
+func (pq PageQuery) GetAfter() *string {
+	return pq.After
+}
+
+// ValidateSort checks the sort columns of query against the
+// `sortable` tag of the embedded PageQuery when present,
+// or the columns of the entity otherwise.
+func ValidateSort(query Query, columns []string) error {
+	if query.GetSort() == "" {
+		return nil
+	}
+	if sortable, ok := lookupSortable(reflect.TypeOf(query)); ok {
+		columns = strings.Split(sortable, ",")
+	}
+	for _, group := range SortRgx.FindAllStringSubmatch(query.GetSort(), -1) {
+		if !containsColumn(columns, group[1]) {
+			return fmt.Errorf("%w: %s", ErrInvalidSort, group[1])
+		}
+	}
+	return nil
+}
+
+func lookupSortable(queryType reflect.Type) (string, bool) {
+	if queryType.Kind() == reflect.Ptr {
+		queryType = queryType.Elem()
+	}
+	if queryType.Kind() != reflect.Struct {
+		return "", false
+	}
+	if field, ok := queryType.FieldByName("PageQuery"); ok && field.Anonymous {
+		return field.Tag.Lookup("sortable")
+	}
+	return "", false
+}
+
+func containsColumn(columns []string, column string) bool {
+	for _, col := range columns {
+		if strings.TrimSpace(col) == column {
+			return true
+		}
+	}
+	return false
+}
@@ -11,6 +11,7 @@
 package core
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -58,3 +59,34 @@ func TestBuildPageClause(t *testing.T) {
 		}
 	})
 }
+
+type sortableQuery struct {
+	PageQuery `sortable:"score, memo"`
+}
+
+func TestValidateSort(t *testing.T) {
+	columns := []string{"id", "score", "memo"}
+	tests := []struct {
+		name  string
+		query Query
+		err   string
+	}{
+		{"Accept the entity columns", PageQuery{Sort: "id,desc;score"}, ""},
+		{"Accept the empty sort", PageQuery{}, ""},
+		{"Reject the unknown column", PageQuery{Sort: "id;password,desc"}, "invalid sort column: password"},
+		{"Reject the injected tokens", PageQuery{Sort: "id;(select 1)"}, "invalid sort column: select"},
+		{"Accept the sortable columns", &sortableQuery{PageQuery{Sort: "memo,asc;score"}}, ""},
+		{"Reject the column not sortable", sortableQuery{PageQuery{Sort: "id"}}, "invalid sort column: id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSort(tt.query, columns)
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Errorf("\nExpected: %s\nBut got : %v", tt.err, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSort) {
+				t.Errorf("\nExpected: %v\nBut got : %v", ErrInvalidSort, err)
+			}
+		})
+	}
+}
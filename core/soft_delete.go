@@ -0,0 +1,28 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import "context"
+
+type withDeletedKey struct{}
+
+// WithDeleted returns a copy of ctx reading the soft-deleted records
+// as well for the entities with a `softdelete` field, which is kept
+// out of the query objects since they are bound from the requests.
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withDeletedKey{}, true)
+}
+
+// IsWithDeleted reports whether ctx reads the soft-deleted records.
+func IsWithDeleted(ctx context.Context) bool {
+	withDeleted, _ := ctx.Value(withDeletedKey{}).(bool)
+	return withDeleted
+}
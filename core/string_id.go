@@ -0,0 +1,57 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"fmt"
+)
+
+// StringId is the id assigned by the client before the insert,
+// or generated by the IdGenerator registered for the entity.
+type StringId struct {
+	Id string `json:"id,omitempty"`
+}
+
+func (e StringId) GetId() any {
+	return e.Id
+}
+
+func NewStringId(id string) StringId {
+	return StringId{Id: id}
+}
+
+func (e StringId) SetId(self any, id any) error {
+	self.(stringIdSetter).setId(fmt.Sprint(id))
+	return nil
+}
+
+type stringIdSetter interface {
+	setId(id string)
+}
+
+func (e *StringId) setId(id string) {
+	e.Id = id
+}
+
+// UUIDId is the string id generated as a UUID before the insert,
+// which is a version 7 UUID unless another IdGenerator, such as
+// UUIDv4, is registered for the entity.
+type UUIDId struct {
+	StringId
+}
+
+func NewUUIDId(id string) UUIDId {
+	return UUIDId{StringId{Id: id}}
+}
+
+func (e UUIDId) IdGenerator() IdGenerator {
+	return UUIDv7
+}
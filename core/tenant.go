@@ -0,0 +1,63 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// ErrTenantRequired is returned when an entity with the
+// `tenant` field is accessed without a tenant in ctx.
+var ErrTenantRequired = errors.New("tenant is required")
+
+type tenantIdKey struct{}
+
+// WithTenantId returns a copy of ctx carrying the id of the current
+// tenant to scope the access to the entities with a `tenant` field.
+func WithTenantId(ctx context.Context, tenantId any) context.Context {
+	return context.WithValue(ctx, tenantIdKey{}, tenantId)
+}
+
+// GetTenantId returns the id of the current tenant in ctx, or nil if absent.
+func GetTenantId(ctx context.Context) any {
+	return ctx.Value(tenantIdKey{})
+}
+
+// FindTenantField returns the field tagged by `tenant` of the entity type.
+func FindTenantField(entityType reflect.Type) (FieldMetadata, bool) {
+	for _, md := range BuildFieldMetas(entityType) {
+		if _, ok := md.Field.Tag.Lookup("tenant"); ok {
+			return md, true
+		}
+	}
+	return FieldMetadata{}, false
+}
+
+// RequireTenantId returns the id of the current tenant in ctx
+// for the entity with a tenant field, or ErrTenantRequired if absent.
+func RequireTenantId(ctx context.Context, tenantField string) (any, error) {
+	if tenantField == "" {
+		return nil, nil
+	}
+	tenantId := GetTenantId(ctx)
+	if tenantId == nil {
+		return nil, ErrTenantRequired
+	}
+	return tenantId, nil
+}
+
+// FillTenant sets tenantId to the field named tenantField
+// of the entity pointed by self.
+func FillTenant(self any, tenantField string, tenantId any) {
+	setAuditValue(reflect.ValueOf(self).Elem(), tenantField, tenantId)
+}
@@ -11,10 +11,105 @@
 package core
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 )
 
+// Propagation decides how a transaction is started
+// when ctx already carries a transaction.
+type Propagation int
+
+const (
+	// PropagationRequired joins the existing transaction,
+	// or starts a new one when there is none.
+	PropagationRequired Propagation = iota
+	// PropagationRequiresNew always starts a new transaction
+	// independent of the existing one.
+	PropagationRequiresNew
+	// PropagationNested starts a nested transaction by a savepoint
+	// in the existing transaction, which is rolled back alone.
+	// It starts a new transaction when there is none.
+	PropagationNested
+	// PropagationMandatory joins the existing transaction, and
+	// fails with ErrNoTransaction when there is none.
+	PropagationMandatory
+)
+
+// ErrNoTransaction is returned when a transaction with
+// PropagationMandatory is started without an existing one.
+var ErrNoTransaction = errors.New("no existing transaction")
+
+// ErrSavePointNotSupported is returned when the
+// database does not support the savepoints.
+var ErrSavePointNotSupported = errors.New("savepoint is not supported")
+
+// TxOptions holds the options to start a transaction. The isolation
+// level and the read-only mode only apply to a new transaction.
+type TxOptions struct {
+	Propagation Propagation
+	Isolation   sql.IsolationLevel
+	ReadOnly    bool
+}
+
+type TxOption func(options *TxOptions)
+
+func WithPropagation(propagation Propagation) TxOption {
+	return func(options *TxOptions) {
+		options.Propagation = propagation
+	}
+}
+
+func WithIsolation(isolation sql.IsolationLevel) TxOption {
+	return func(options *TxOptions) {
+		options.Isolation = isolation
+	}
+}
+
+func WithReadOnly() TxOption {
+	return func(options *TxOptions) {
+		options.ReadOnly = true
+	}
+}
+
+func BuildTxOptions(opts ...TxOption) TxOptions {
+	options := TxOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// TxKey is the key by which the TransactionContext returns itself
+// from Value, so it is still found in the contexts derived from it.
+type TxKey struct{}
+
+// ReadTransaction reads the active TransactionContext from ctx.
+func ReadTransaction(ctx context.Context) (TransactionContext, bool) {
+	tc, ok := ctx.Value(TxKey{}).(TransactionContext)
+	return tc, ok
+}
+
+// TxSynchronization is implemented by the TransactionContexts
+// which invoke the callbacks after the commit or the rollback.
+type TxSynchronization interface {
+	AfterCompletion(callback func())
+}
+
+// AfterCompletion registers callback to the transaction in ctx to
+// invoke after it completes, or invokes callback at once when ctx
+// has no transaction supporting the synchronization.
+func AfterCompletion(ctx context.Context, callback func()) {
+	if tc, ok := ReadTransaction(ctx); ok {
+		if ts, ok := tc.(TxSynchronization); ok {
+			ts.AfterCompletion(callback)
+			return
+		}
+	}
+	callback()
+}
+
 type RollbackError struct {
 	Err    error
 	Origin error
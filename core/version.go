@@ -0,0 +1,73 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ErrOptimisticLock is returned when an update by the version
+// affects no record, which has been modified by another one.
+var ErrOptimisticLock = errors.New("optimistic lock failure")
+
+// FindVersionField returns the field tagged by `version` of the entity type.
+func FindVersionField(entityType reflect.Type) (reflect.StructField, bool) {
+	for _, md := range BuildFieldMetas(entityType) {
+		if _, ok := md.Field.Tag.Lookup("version"); ok {
+			return md.Field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// SetVersion sets version to the field tagged by `version`
+// of the entity pointed by self, and does nothing when
+// the entity has no version field. ErrInvalidId is returned
+// when version can't be parsed into the version field.
+func SetVersion(self any, version string) error {
+	rv := reflect.ValueOf(self).Elem()
+	vf, ok := FindVersionField(rv.Type())
+	if !ok {
+		return nil
+	}
+	field := rv.FieldByName(vf.Name)
+	fieldType := field.Type()
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	value := reflect.New(fieldType).Elem()
+	var err error
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var v int64
+		if v, err = strconv.ParseInt(version, 10, fieldType.Bits()); err == nil {
+			value.SetInt(v)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var v uint64
+		if v, err = strconv.ParseUint(version, 10, fieldType.Bits()); err == nil {
+			value.SetUint(v)
+		}
+	default:
+		err = errors.New("unsupported version type " + fieldType.String())
+	}
+	if err != nil {
+		return fmt.Errorf("%w: version %s, %v", ErrInvalidId, version, err)
+	}
+	if field.Kind() == reflect.Ptr {
+		value = value.Addr()
+	}
+	field.Set(value)
+	return nil
+}
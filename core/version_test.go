@@ -0,0 +1,71 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type versionEntity struct {
+	IntId
+	Version *int64 `version:""`
+}
+
+type revisionEntity struct {
+	Revision int `version:""`
+}
+
+type uintVersionEntity struct {
+	Version *uint64 `version:""`
+}
+
+type labelVersionEntity struct {
+	Label string `version:""`
+}
+
+func TestSetVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		entity  any
+		version string
+		want    any
+	}{
+		{"Support pointer field", &versionEntity{}, "3", &versionEntity{Version: P(int64(3))}},
+		{"Support int field", &revisionEntity{}, "4", &revisionEntity{Revision: 4}},
+		{"Support uint field", &uintVersionEntity{}, "6", &uintVersionEntity{Version: P(uint64(6))}},
+		{"Ignore entity without version", &IntId{Id: 1}, "5", &IntId{Id: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetVersion(tt.entity, tt.version); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tt.entity, tt.want) {
+				t.Errorf("SetVersion() = %v, want %v", tt.entity, tt.want)
+			}
+		})
+	}
+	invalids := []struct {
+		entity  any
+		version string
+	}{
+		{&revisionEntity{}, "v1"},
+		{&uintVersionEntity{}, "-1"},
+		{&labelVersionEntity{}, "1"},
+	}
+	for _, tt := range invalids {
+		if err := SetVersion(tt.entity, tt.version); !errors.Is(err, ErrInvalidId) {
+			t.Errorf("SetVersion(%T, %s) = %v, want %v", tt.entity, tt.version, err, ErrInvalidId)
+		}
+	}
+}
@@ -15,11 +15,11 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/doytowin/goooqo/core"
 	"github.com/doytowin/goooqo/rdb"
-	log "github.com/sirupsen/logrus"
 )
 
-const format = "conditions = append(conditions, \"%s %s ?\")"
+const format = "conditions = append(conditions, d.Quote(\"%s\")+\" %s ?\")"
 
 type SqlGenerator struct {
 	*generator
@@ -33,9 +33,9 @@ func init() {
 	sqlOpMap["Ge"] = operator{name: "Ge", sign: ">="}
 	sqlOpMap["Lt"] = operator{name: "Lt", sign: "<"}
 	sqlOpMap["Le"] = operator{name: "Le", sign: "<="}
-	sqlOpMap["In"] = operator{name: "In", sign: "IN", format: "conditions = append(conditions, \"%s %s (\"+strings.Join(phs, \", \")+\")\")"}
-	sqlOpMap["NotIn"] = operator{name: "NotIn", sign: "NOT IN", format: "conditions = append(conditions, \"%s %s (\"+strings.Join(phs, \", \")+\")\")"}
-	sqlOpMap["Null"] = operator{name: "Null", sign: "IS NULL", format: "conditions = append(conditions, \"%s %s\")"}
+	sqlOpMap["In"] = operator{name: "In", sign: "IN", format: "conditions = append(conditions, d.Quote(\"%s\")+\" %s (\"+strings.Join(phs, \", \")+\")\")"}
+	sqlOpMap["NotIn"] = operator{name: "NotIn", sign: "NOT IN", format: "conditions = append(conditions, d.Quote(\"%s\")+\" %s (\"+strings.Join(phs, \", \")+\")\")"}
+	sqlOpMap["Null"] = operator{name: "Null", sign: "IS NULL", format: "conditions = append(conditions, d.Quote(\"%s\")+\" %s\")"}
 	sqlOpMap["Like"] = operator{name: "Like", sign: "LIKE", format: format}
 	sqlOpMap["NotLike"] = operator{name: "NotLike", sign: "NOT LIKE", format: format}
 	sqlOpMap["Contain"] = operator{name: "Contain", sign: "LIKE", format: format}
@@ -44,7 +44,7 @@ func init() {
 	sqlOpMap["NotStart"] = operator{name: "NotStart", sign: "NOT LIKE", format: format}
 	sqlOpMap["End"] = operator{name: "End", sign: "LIKE", format: format}
 	sqlOpMap["NotEnd"] = operator{name: "NotEnd", sign: "NOT LIKE", format: format}
-	sqlOpMap["Rx"] = operator{name: "Rx", sign: "REGEXP", format: format}
+	sqlOpMap["Rx"] = operator{name: "Rx", sign: "REGEXP", format: "conditions = append(conditions, d.BuildRegexp(d.Quote(\"%[1]s\"), \"?\"))"}
 	opMap["sql"] = sqlOpMap
 }
 
@@ -56,7 +56,7 @@ func NewSqlGenerator() *SqlGenerator {
 
 func (g *SqlGenerator) appendBuildMethod(ts *ast.TypeSpec) {
 	g.WriteString(NewLine)
-	g.writeInstruction("func (q %s) BuildConditions() ([]string, []any) {", ts.Name)
+	g.writeInstruction("func (q %s) BuildConditions(d DbDialect) ([]string, []any) {", ts.Name)
 	g.appendFuncBody(ts)
 	g.writeInstruction("}")
 }
@@ -85,24 +85,31 @@ func (g *SqlGenerator) appendStruct(stp *ast.StructType) {
 func (g *SqlGenerator) appendCondition(field *ast.Field, fieldName string) {
 	column, op := g.suffixMatch(fieldName)
 
+	var tag reflect.StructTag
 	if field.Tag != nil {
+		tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+	}
+	if columnTag := core.ColumnTag(tag); columnTag == "-" {
+		return
+	} else if columnTag != "" {
+		column = columnTag
+	}
+
+	if isCustomized(tag) {
 		g.appendIfStartNil(fieldName)
-		tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
 		if subqueryTag, ok := tag.Lookup("subquery"); ok {
 			fpSubquery := rdb.BuildBySubqueryTag(subqueryTag, fieldName)
-			subSelect := fpSubquery.Subquery()
+			subSelect := fpSubquery.Subquery(&quoteByDialect{})
 			g.genSubquery(fieldName, subSelect)
 		} else if _, ok = tag.Lookup("select"); ok {
 			fpSubquery := rdb.BuildBySelectTag(tag, fieldName)
-			subSelect := fpSubquery.Subquery()
+			subSelect := fpSubquery.Subquery(&quoteByDialect{})
 			g.genSubquery(fieldName, subSelect)
 		} else if conditionTag, ok := tag.Lookup("condition"); ok {
 			g.appendIfBody("conditions = append(conditions, \"%s\")", conditionTag)
 			for i := 0; i < strings.Count(conditionTag, "?"); i++ {
 				g.appendArg(fieldName)
 			}
-		} else {
-			log.Warn("Unsupported field: ", fieldName, " ", field.Type)
 		}
 	} else if strings.Contains(op.sign, "NULL") {
 		g.appendIfStartNil(fieldName)
@@ -123,12 +130,12 @@ func (g *SqlGenerator) appendCondition(field *ast.Field, fieldName string) {
 		g.appendIfBody(op.format, column, op.sign)
 	} else if strings.HasSuffix(fieldName, "Or") {
 		g.appendIfStartNil(fieldName)
-		g.appendIfBody("cond, args0 := BuildConditions(q.%s, \"(\", \" OR \", \")\")", fieldName)
+		g.appendIfBody("cond, args0 := BuildConditionsBy(d, q.%s, \"(\", \" OR \", \")\")", fieldName)
 		g.appendIfBody("conditions = append(conditions, cond)")
 		g.appendIfBody("args = append(args, args0...)")
 	} else if strings.HasSuffix(fieldName, "And") {
 		g.appendIfStartNil(fieldName)
-		g.appendIfBody("cond, args0 := BuildConditions(q.%s, \"\", \" AND \", \"\")", fieldName)
+		g.appendIfBody("cond, args0 := BuildConditionsBy(d, q.%s, \"\", \" AND \", \"\")", fieldName)
 		g.appendIfBody("conditions = append(conditions, cond)")
 		g.appendIfBody("args = append(args, args0...)")
 	} else if strings.HasSuffix(op.sign, "LIKE") {
@@ -151,12 +158,34 @@ func (g *SqlGenerator) appendCondition(field *ast.Field, fieldName string) {
 	g.appendIfEnd()
 }
 
+// isCustomized reports whether the condition of the field
+// is customized by the tag instead of resolved by the suffix.
+func isCustomized(tag reflect.StructTag) bool {
+	for _, key := range []string{"subquery", "select", "condition"} {
+		if _, ok := tag.Lookup(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *SqlGenerator) genSubquery(fieldName string, subSelect string) {
-	g.appendIfBody("where, args1 := BuildWhereClause(q.%s)", fieldName)
-	g.appendIfBody("conditions = append(conditions, \"" + subSelect + "\"+where+\")\")")
+	condition := strings.TrimPrefix("\""+subSelect+"\"+where+\")\"", "\"\"+")
+	g.appendIfBody("where, args1 := BuildWhereClauseBy(d, q.%s)", fieldName)
+	g.appendIfBody("conditions = append(conditions, " + strings.ReplaceAll(condition, "+\"\"", "") + ")")
 	g.appendIfBody("args = append(args, args1...)")
 }
 
+// quoteByDialect generates the code to quote the identifiers of
+// the subquery by the dialect passed to BuildConditions at runtime.
+type quoteByDialect struct {
+	rdb.BaseDialect
+}
+
+func (d *quoteByDialect) Quote(identifier string) string {
+	return "\"+d.Quote(\"" + identifier + "\")+\""
+}
+
 func (g *SqlGenerator) appendArg(fieldName string) {
 	g.appendIfBody("args = append(args, *q.%s)", fieldName)
 }
@@ -3,11 +3,11 @@ package main
 import . "github.com/doytowin/goooqo/rdb"
 import "strings"
 
-func (q UserQuery) BuildConditions() ([]string, []any) {
+func (q UserQuery) BuildConditions(d DbDialect) ([]string, []any) {
 	conditions := make([]string, 0, 4)
 	args := make([]any, 0, 4)
 	if q.IdGt != nil {
-		conditions = append(conditions, "id > ?")
+		conditions = append(conditions, d.Quote("id")+" > ?")
 		args = append(args, *q.IdGt)
 	}
 	if q.IdIn != nil {
@@ -16,7 +16,7 @@ func (q UserQuery) BuildConditions() ([]string, []any) {
 			args = append(args, arg)
 			phs = append(phs, "?")
 		}
-		conditions = append(conditions, "id IN ("+strings.Join(phs, ", ")+")")
+		conditions = append(conditions, d.Quote("id")+" IN ("+strings.Join(phs, ", ")+")")
 	}
 	if q.IdNotIn != nil {
 		phs := make([]string, 0, len(*q.IdNotIn))
@@ -24,7 +24,7 @@ func (q UserQuery) BuildConditions() ([]string, []any) {
 			args = append(args, arg)
 			phs = append(phs, "?")
 		}
-		conditions = append(conditions, "id NOT IN ("+strings.Join(phs, ", ")+")")
+		conditions = append(conditions, d.Quote("id")+" NOT IN ("+strings.Join(phs, ", ")+")")
 	}
 	if q.Cond != nil {
 		conditions = append(conditions, "(score = ? OR memo = ?)")
@@ -32,84 +32,88 @@ func (q UserQuery) BuildConditions() ([]string, []any) {
 		args = append(args, *q.Cond)
 	}
 	if q.ScoreLt != nil {
-		conditions = append(conditions, "score < ?")
+		conditions = append(conditions, d.Quote("score")+" < ?")
 		args = append(args, *q.ScoreLt)
 	}
 	if q.MemoNull != nil {
 		if *q.MemoNull {
-			conditions = append(conditions, "memo IS NULL")
+			conditions = append(conditions, d.Quote("memo")+" IS NULL")
 		} else {
-			conditions = append(conditions, "memo IS NOT NULL")
+			conditions = append(conditions, d.Quote("memo")+" IS NOT NULL")
 		}
 	}
 	if q.Deleted != nil {
-		conditions = append(conditions, "deleted = ?")
+		conditions = append(conditions, d.Quote("deleted")+" = ?")
 		args = append(args, *q.Deleted)
 	}
 	if q.MemoLike != nil && *q.MemoLike != "" {
-		conditions = append(conditions, "memo LIKE ?")
+		conditions = append(conditions, d.Quote("memo")+" LIKE ?")
 		args = append(args, *q.MemoLike)
 	}
 	if q.MemoNotLike != nil && *q.MemoNotLike != "" {
-		conditions = append(conditions, "memo NOT LIKE ?")
+		conditions = append(conditions, d.Quote("memo")+" NOT LIKE ?")
 		args = append(args, *q.MemoNotLike)
 	}
 	if q.MemoContain != nil && *q.MemoContain != "" {
-		conditions = append(conditions, "memo LIKE ?")
+		conditions = append(conditions, d.Quote("memo")+" LIKE ?")
 		args = append(args, "%"+*q.MemoContain+"%")
 	}
 	if q.MemoNotContain != nil && *q.MemoNotContain != "" {
-		conditions = append(conditions, "memo NOT LIKE ?")
+		conditions = append(conditions, d.Quote("memo")+" NOT LIKE ?")
 		args = append(args, "%"+*q.MemoNotContain+"%")
 	}
 	if q.MemoStart != nil && *q.MemoStart != "" {
-		conditions = append(conditions, "memo LIKE ?")
+		conditions = append(conditions, d.Quote("memo")+" LIKE ?")
 		args = append(args, *q.MemoStart+"%")
 	}
 	if q.MemoNotStart != nil && *q.MemoNotStart != "" {
-		conditions = append(conditions, "memo NOT LIKE ?")
+		conditions = append(conditions, d.Quote("memo")+" NOT LIKE ?")
 		args = append(args, *q.MemoNotStart+"%")
 	}
 	if q.MemoEnd != nil && *q.MemoEnd != "" {
-		conditions = append(conditions, "memo LIKE ?")
+		conditions = append(conditions, d.Quote("memo")+" LIKE ?")
 		args = append(args, "%"+*q.MemoEnd)
 	}
 	if q.MemoNotEnd != nil && *q.MemoNotEnd != "" {
-		conditions = append(conditions, "memo NOT LIKE ?")
+		conditions = append(conditions, d.Quote("memo")+" NOT LIKE ?")
 		args = append(args, "%"+*q.MemoNotEnd)
 	}
 	if q.MemoRx != nil {
-		conditions = append(conditions, "memo REGEXP ?")
+		conditions = append(conditions, d.BuildRegexp(d.Quote("memo"), "?"))
 		args = append(args, *q.MemoRx)
 	}
+	if q.RemarkStart != nil && *q.RemarkStart != "" {
+		conditions = append(conditions, d.Quote("memo")+" LIKE ?")
+		args = append(args, *q.RemarkStart+"%")
+	}
 	if q.Or != nil {
-		cond, args0 := BuildConditions(q.Or, "(", " OR ", ")")
+		cond, args0 := BuildConditionsBy(d, q.Or, "(", " OR ", ")")
 		conditions = append(conditions, cond)
 		args = append(args, args0...)
 	}
 	if q.And != nil {
-		cond, args0 := BuildConditions(q.And, "", " AND ", "")
+		cond, args0 := BuildConditionsBy(d, q.And, "", " AND ", "")
 		conditions = append(conditions, cond)
 		args = append(args, args0...)
 	}
 	if q.ScoreLtAvg != nil {
-		where, args1 := BuildWhereClause(q.ScoreLtAvg)
-		conditions = append(conditions, "score < (SELECT avg(score) FROM t_user"+where+")")
+		where, args1 := BuildWhereClauseBy(d, q.ScoreLtAvg)
+		conditions = append(conditions, d.Quote("score")+" < (SELECT avg(score) FROM "+d.Quote("t_user")+where+")")
 		args = append(args, args1...)
 	}
 	if q.ScoreLtAny != nil {
-		where, args1 := BuildWhereClause(q.ScoreLtAny)
-		conditions = append(conditions, "score < ANY(SELECT score FROM t_user"+where+")")
+		where, args1 := BuildWhereClauseBy(d, q.ScoreLtAny)
+		conditions = append(conditions, d.Quote("score")+" < ANY(SELECT score FROM "+d.Quote("t_user")+where+")")
 		args = append(args, args1...)
 	}
 	if q.ScoreLtAll != nil {
-		where, args1 := BuildWhereClause(q.ScoreLtAll)
-		conditions = append(conditions, "score < ALL(SELECT score FROM t_user"+where+")")
+		where, args1 := BuildWhereClauseBy(d, q.ScoreLtAll)
+		conditions = append(conditions, d.Quote("score")+" < ALL(SELECT score FROM "+d.Quote("t_user")+where+")")
 		args = append(args, args1...)
 	}
 	if q.ScoreGtAvg != nil {
-		where, args1 := BuildWhereClause(q.ScoreGtAvg)
-		conditions = append(conditions, "score > (SELECT avg(score) FROM t_user"+where+")")
+		where, args1 := BuildWhereClauseBy(d, q.ScoreGtAvg)
+		conditions = append(conditions, d.Quote("score")+" > (SELECT avg(score) FROM "+d.Quote("t_user")+where+")")
 		args = append(args, args1...)
 	}
 	return conditions, args
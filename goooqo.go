@@ -32,6 +32,28 @@ type Int64Id = core.Int64Id
 
 var NewInt64Id = core.NewInt64Id
 
+type StringId = core.StringId
+
+var NewStringId = core.NewStringId
+
+type UUIDId = core.UUIDId
+
+var NewUUIDId = core.NewUUIDId
+
+var SetCompositeId = core.SetCompositeId
+
+type IdGenerator = core.IdGenerator
+
+var UUIDv4 = core.UUIDv4
+
+var UUIDv7 = core.UUIDv7
+
+var NewSnowflake = core.NewSnowflake
+
+func RegisterIdGenerator[E Entity](generator IdGenerator) {
+	core.RegisterIdGenerator[E](generator)
+}
+
 var Config = core.Config
 
 func P[T any](t T) *T { return &t }
@@ -40,14 +62,35 @@ type DataAccess[E Entity] core.DataAccess[E]
 
 type TransactionManager = core.TransactionManager
 
+type TxOption = core.TxOption
+
+var WithPropagation = core.WithPropagation
+
+var WithIsolation = core.WithIsolation
+
+var WithReadOnly = core.WithReadOnly
+
+const (
+	PropagationRequired    = core.PropagationRequired
+	PropagationRequiresNew = core.PropagationRequiresNew
+	PropagationNested      = core.PropagationNested
+	PropagationMandatory   = core.PropagationMandatory
+)
+
 var Connect = rdb.Connect
 
 var Disconnect = rdb.Disconnect
 
 var NewTransactionManager = rdb.NewTransactionManager
 
+var NewRoutingTransactionManager = rdb.NewRoutingTransactionManager
+
+var WithReadYourWrites = rdb.WithReadYourWrites
+
 var NewRdbAssociationService = rdb.NewRdbAssociationService
 
+var BindDialect = rdb.BindDialect
+
 func NewDataAccess[E Entity](db rdb.Connection) DataAccess[E] {
 	return rdb.NewDataAccess[E](db)
 }
@@ -56,6 +99,26 @@ func NewTxDataAccess[E Entity](tm core.TransactionManager) DataAccess[E] {
 	return rdb.NewTxDataAccess[E](tm)
 }
 
+type Interceptor = core.Interceptor
+
+var LoggingInterceptor = core.LoggingInterceptor
+
+var TimingInterceptor = core.TimingInterceptor
+
+func Wrap[E Entity](da DataAccess[E], interceptors ...Interceptor) DataAccess[E] {
+	return core.Wrap[E](da, interceptors...)
+}
+
+type CacheStore = core.CacheStore
+
+var NewLRUCache = core.NewLRUCache
+
+var WithQueryCache = core.WithQueryCache
+
+func Cache[E Entity](da DataAccess[E], store CacheStore, opts ...core.CacheOption) DataAccess[E] {
+	return core.Cache[E](da, store, opts...)
+}
+
 var RegisterConverter = web.RegisterConverter
 
 func BuildRestService[E Entity, Q Query](prefix string, dataAccess DataAccess[E]) {
@@ -42,6 +42,8 @@ type UserQuery struct {
 	MemoEnd        *string
 	MemoNotEnd     *string
 	MemoRx         *string
+	RemarkStart    *string `column:"memo"`
+	Password       *string `column:"-"`
 
 	Or  *UserQuery
 	And *UserQuery
@@ -18,6 +18,7 @@ import (
 
 	. "github.com/doytowin/goooqo/core"
 	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
 	. "go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -46,7 +47,13 @@ func CombineConditions(connector string, a A) D {
 }
 
 type mongoDataAccess[E MongoEntity] struct {
-	collection *mongo.Collection
+	collection  *mongo.Collection
+	columns     []string
+	softDelete  string
+	audit       AuditFields
+	created     []string
+	tenant      string
+	tenantField string
 }
 
 func NewMongoDataAccess[E MongoEntity](tm TransactionManager) TxDataAccess[E] {
@@ -55,9 +62,19 @@ func NewMongoDataAccess[E MongoEntity](tm TransactionManager) TxDataAccess[E] {
 	collection := client.Database(entity.Database()).Collection(entity.Collection())
 	entityType := reflect.TypeOf(entity)
 	createIndex(entityType, collection)
+	m := &mongoDataAccess[E]{
+		collection: collection,
+		columns:    buildColumns(entityType),
+		softDelete: findSoftDelete(entityType),
+		audit:      BuildAuditFields(entityType),
+	}
+	m.created = findCreatedFields(entityType, m.audit)
+	if md, ok := FindTenantField(entityType); ok {
+		m.tenant, m.tenantField = readFieldName(md.Field), md.Field.Name
+	}
 	return TxDataAccess[E]{
 		TransactionManager: tm,
-		DataAccess:         &mongoDataAccess[E]{collection},
+		DataAccess:         m,
 	}
 }
 
@@ -85,6 +102,49 @@ func createIndexModel(entityType reflect.Type) D {
 	return ret
 }
 
+// buildColumns collects the field names of the documents,
+// which are the columns allowed to sort by.
+func buildColumns(entityType reflect.Type) []string {
+	columns := make([]string, 0, entityType.NumField()+1)
+	for i := 0; i < entityType.NumField(); i++ {
+		field := entityType.Field(i)
+		if name := readFieldName(field); name != "" {
+			columns = append(columns, name)
+		} else if field.Type.Kind() == reflect.Struct {
+			columns = append(columns, buildColumns(field.Type)...)
+		}
+	}
+	return columns
+}
+
+// findSoftDelete returns the field name of the field tagged
+// by `softdelete`, or an empty string if absent.
+func findSoftDelete(entityType reflect.Type) string {
+	for i := 0; i < entityType.NumField(); i++ {
+		field := entityType.Field(i)
+		if _, ok := field.Tag.Lookup("softdelete"); ok {
+			return readFieldName(field)
+		} else if field.Type.Kind() == reflect.Struct {
+			if name := findSoftDelete(field.Type); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// findCreatedFields returns the field names of
+// the audit fields filled only on creation.
+func findCreatedFields(entityType reflect.Type, audit AuditFields) []string {
+	created := make([]string, 0, 2)
+	for _, fieldName := range []string{audit.CreatedAt, audit.CreatedBy} {
+		if field, ok := entityType.FieldByName(fieldName); ok {
+			created = append(created, readFieldName(field))
+		}
+	}
+	return created
+}
+
 func resolveColumnTag(columnTag string, fieldName string) (string, bool) {
 	values := strings.Split(columnTag, ",")
 	column := values[0]
@@ -97,22 +157,92 @@ func resolveColumnTag(columnTag string, fieldName string) (string, bool) {
 
 func (m *mongoDataAccess[E]) Get(ctx context.Context, id any) (*E, error) {
 	ID, err := ResolveId(id)
+	var filter D
 	if NoError(err) {
+		filter, err = m.buildIdFilter(ctx, ID)
+	}
+	if err == nil {
 		e := *new(E)
-		err = m.collection.FindOne(ctx, buildIdFilter(ID)).Decode(&e)
-		if NoError(err) {
+		err = m.collection.FindOne(ctx, filter).Decode(&e)
+		if err == nil {
 			return &e, err
 		}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = ErrNotFound
+		}
 	}
 	return nil, err
 }
 
 func (m *mongoDataAccess[E]) Delete(ctx context.Context, id any) (int64, error) {
 	ID, err := ResolveId(id)
-	if NoError(err) {
-		return unwrap(m.collection.DeleteOne(ctx, buildIdFilter(ID)))
+	if !NoError(err) {
+		return 0, err
 	}
-	return 0, err
+	filter, err := m.buildIdFilter(ctx, ID)
+	if err != nil {
+		return 0, err
+	}
+	self, err := NewDeleteHookEntity[E](ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	var cnt int64
+	if m.softDelete != "" {
+		cnt, err = unwrapPatch(m.collection.UpdateOne(ctx, filter, m.buildSoftDelete()))
+	} else {
+		cnt, err = unwrap(m.collection.DeleteOne(ctx, filter))
+	}
+	if err == nil && cnt > 0 && self != nil {
+		err = AfterDelete(ctx, self)
+	}
+	return cnt, err
+}
+
+// buildSoftDelete builds the update to mark the documents as deleted.
+func (m *mongoDataAccess[E]) buildSoftDelete() D {
+	return D{{"$set", D{{m.softDelete, true}}}}
+}
+
+// filterDeleted appends the condition to filter out the
+// soft-deleted documents to filter unless the entity has
+// no `softdelete` field or ctx reads them as well.
+func (m *mongoDataAccess[E]) filterDeleted(ctx context.Context, filter D) D {
+	if m.softDelete == "" || IsWithDeleted(ctx) {
+		return filter
+	}
+	return append(filter, D{{m.softDelete, D{{"$ne", true}}}}...)
+}
+
+// filterTenant appends the condition of the tenant in ctx
+// to filter for the entity with a `tenant` field.
+func (m *mongoDataAccess[E]) filterTenant(ctx context.Context, filter D) (D, error) {
+	tenantId, err := RequireTenantId(ctx, m.tenantField)
+	if err != nil || tenantId == nil {
+		return filter, err
+	}
+	return append(filter, D{{m.tenant, tenantId}}...), nil
+}
+
+// fillTenant stamps the tenant in ctx on the entity pointed by self.
+func (m *mongoDataAccess[E]) fillTenant(ctx context.Context, self any) error {
+	tenantId, err := RequireTenantId(ctx, m.tenantField)
+	if err == nil && tenantId != nil {
+		FillTenant(self, m.tenantField, tenantId)
+	}
+	return err
+}
+
+// buildFilter builds the filter of query without the soft-deleted
+// documents and the documents of the other tenants.
+func (m *mongoDataAccess[E]) buildFilter(ctx context.Context, query Query) (D, error) {
+	return m.filterTenant(ctx, m.filterDeleted(ctx, buildFilter(query)))
+}
+
+// buildIdFilter builds the filter of the _id without the soft-deleted
+// documents and the documents of the other tenants.
+func (m *mongoDataAccess[E]) buildIdFilter(ctx context.Context, objectID any) (D, error) {
+	return m.filterTenant(ctx, m.filterDeleted(ctx, buildIdFilter(objectID)))
 }
 
 func buildIdFilter(objectID any) D {
@@ -130,13 +260,63 @@ func ResolveId(id any) (ObjectID, error) {
 }
 
 func (m *mongoDataAccess[E]) Query(ctx context.Context, query Query) ([]E, error) {
-	filter := buildFilter(query)
+	filter, err := m.buildFilter(ctx, query)
+	if err != nil {
+		return nil, err
+	}
 	return m.doQuery(ctx, query, filter)
 }
 
 func (m *mongoDataAccess[E]) doQuery(ctx context.Context, query Query, filter D) ([]E, error) {
-	result := make([]E, 0, query.GetPageSize())
+	filter, err := m.resolveFilter(query, filter)
+	if err != nil {
+		return nil, err
+	}
+	return m.doFind(ctx, query, filter, buildPageOpt(query))
+}
+
+// resolveFilter validates the sort of query and appends
+// the condition of the cursor to filter if present.
+func (m *mongoDataAccess[E]) resolveFilter(query Query, filter D) (D, error) {
+	if err := ValidateSort(query, m.columns); err != nil {
+		return nil, err
+	}
+	if after, ok := ReadAfter(query); ok {
+		return buildCursorFilter(filter, query.GetSort(), after)
+	}
+	return filter, nil
+}
+
+// Iterate decodes the documents matching query one at a time and stops
+// at the first error returned by fn or the cancellation of ctx.
+func (m *mongoDataAccess[E]) Iterate(ctx context.Context, query Query, fn func(entity E) error) error {
+	filter, err := m.buildFilter(ctx, query)
+	if err == nil {
+		filter, err = m.resolveFilter(query, filter)
+	}
+	if err != nil {
+		return err
+	}
 	cursor, err := m.collection.Find(ctx, filter, buildPageOpt(query))
+	if err != nil {
+		return err
+	}
+	defer func() { NoError(cursor.Close(ctx)) }()
+	for cursor.Next(ctx) {
+		entity := *new(E)
+		if err = cursor.Decode(&entity); err != nil {
+			return err
+		}
+		if err = fn(entity); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (m *mongoDataAccess[E]) doFind(ctx context.Context, query Query, filter D, opt *options.FindOptions) ([]E, error) {
+	result := make([]E, 0, query.GetPageSize())
+	cursor, err := m.collection.Find(ctx, filter, opt)
 	if NoError(err) {
 		err = cursor.All(ctx, &result)
 	}
@@ -144,6 +324,9 @@ func (m *mongoDataAccess[E]) doQuery(ctx context.Context, query Query, filter D)
 }
 
 func buildPageOpt(query Query) *options.FindOptions {
+	if _, ok := ReadAfter(query); ok {
+		return buildCursorOpt(query)
+	}
 	pageOpt := &options.FindOptions{}
 	if query.NeedPaging() {
 		pageOpt.Limit = PInt64(query.GetPageSize())
@@ -168,7 +351,10 @@ func buildFilter(query Query) D {
 }
 
 func (m *mongoDataAccess[E]) Count(ctx context.Context, query Query) (int64, error) {
-	filter := buildFilter(query)
+	filter, err := m.buildFilter(ctx, query)
+	if err != nil {
+		return 0, err
+	}
 	return m.doCount(ctx, filter)
 }
 
@@ -177,7 +363,10 @@ func (m *mongoDataAccess[E]) doCount(ctx context.Context, filter D) (int64, erro
 }
 
 func (m *mongoDataAccess[E]) DeleteByQuery(ctx context.Context, query Query) (int64, error) {
-	filter := buildFilter(query)
+	filter, err := m.buildFilter(ctx, query)
+	if err != nil {
+		return 0, err
+	}
 	if query.NeedPaging() {
 		IDs, err := m.doQueryIds(ctx, query, filter)
 		if err != nil {
@@ -185,15 +374,24 @@ func (m *mongoDataAccess[E]) DeleteByQuery(ctx context.Context, query Query) (in
 		}
 		filter = D{{MID, D{{"$in", IDs}}}}
 	}
+	if m.softDelete != "" {
+		return unwrapPatch(m.collection.UpdateMany(ctx, filter, m.buildSoftDelete()))
+	}
 	return unwrap(m.collection.DeleteMany(ctx, filter))
 }
 
 func (m *mongoDataAccess[E]) QueryIds(ctx context.Context, query Query) ([]any, error) {
-	filter := buildFilter(query)
+	filter, err := m.buildFilter(ctx, query)
+	if err != nil {
+		return nil, err
+	}
 	return m.doQueryIds(ctx, query, filter)
 }
 
 func (m *mongoDataAccess[E]) doQueryIds(ctx context.Context, query Query, filter any) ([]any, error) {
+	if err := ValidateSort(query, m.columns); err != nil {
+		return nil, err
+	}
 	pageOpt := buildPageOpt(query).SetProjection(M{MID: 1})
 	cursor, err := m.collection.Find(ctx, filter, pageOpt)
 	if NoError(err) {
@@ -219,7 +417,10 @@ func unwrap(result *mongo.DeleteResult, err error) (int64, error) {
 
 func (m *mongoDataAccess[E]) Page(ctx context.Context, query Query) (PageList[E], error) {
 	var count int64
-	filter := buildFilter(query)
+	filter, err := m.buildFilter(ctx, query)
+	if err != nil {
+		return PageList[E]{}, err
+	}
 	data, err := m.doQuery(ctx, query, filter)
 	if NoError(err) {
 		count, err = m.doCount(ctx, filter)
@@ -227,42 +428,115 @@ func (m *mongoDataAccess[E]) Page(ctx context.Context, query Query) (PageList[E]
 	return PageList[E]{List: data, Total: count}, err
 }
 
+// CursorPage pages by the cursor in query, or fetches the first
+// page when query is not a CursorQuery or the cursor is absent.
+// The cursor of the next page is returned when the page is full.
+func (m *mongoDataAccess[E]) CursorPage(ctx context.Context, query Query) (CursorPage[E], error) {
+	page := CursorPage[E]{}
+	if err := ValidateSort(query, m.columns); err != nil {
+		return page, err
+	}
+	after, _ := ReadAfter(query)
+	filter, err := m.buildFilter(ctx, query)
+	if err == nil {
+		filter, err = buildCursorFilter(filter, query.GetSort(), after)
+	}
+	if NoError(err) {
+		page.List, err = m.doFind(ctx, query, filter, buildCursorOpt(query))
+	}
+	if NoError(err) && len(page.List) == query.GetPageSize() {
+		page.Next, err = buildCursor(page.List[len(page.List)-1], query.GetSort())
+	}
+	return page, err
+}
+
 func (m *mongoDataAccess[E]) Create(ctx context.Context, entity *E) (int64, error) {
+	if err := m.fillTenant(ctx, entity); err != nil {
+		return 0, err
+	}
+	if err := BeforeCreate(ctx, entity); err != nil {
+		return 0, err
+	}
+	m.audit.FillCreated(ctx, entity)
 	result, err := m.collection.InsertOne(ctx, entity)
+	err = translateError(err)
 	if NoError(err) {
 		err = (*entity).SetId(entity, result.InsertedID)
 	}
+	if err == nil {
+		err = AfterCreate(ctx, entity)
+	}
 	return 0, err
 }
 
 func (m *mongoDataAccess[E]) CreateMulti(ctx context.Context, entities []E) (int64, error) {
 	docs := make([]any, len(entities))
 	for i := range entities {
+		if err := m.fillTenant(ctx, &entities[i]); err != nil {
+			return 0, err
+		}
+		if err := BeforeCreate(ctx, &entities[i]); err != nil {
+			return 0, err
+		}
+		m.audit.FillCreated(ctx, &entities[i])
 		docs[i] = entities[i]
 	}
 
 	result, err := m.collection.InsertMany(ctx, docs)
+	err = translateError(err)
 	if NoError(err) {
 		for i, ID := range result.InsertedIDs {
 			err = entities[i].SetId(&entities[i], ID)
 		}
+		for i := 0; err == nil && i < len(entities); i++ {
+			err = AfterCreate(ctx, &entities[i])
+		}
 		return int64(len(result.InsertedIDs)), err
 	}
 	return 0, err
 }
 
 func (m *mongoDataAccess[E]) Update(ctx context.Context, entity E) (int64, error) {
-	result, err := m.collection.ReplaceOne(ctx, buildIdFilter(entity.GetId()), entity)
+	filter, err := m.buildIdFilter(ctx, entity.GetId())
+	if err != nil {
+		return 0, err
+	}
+	if err = m.fillTenant(ctx, &entity); err != nil {
+		return 0, err
+	}
+	if err = BeforeUpdate(ctx, &entity); err != nil {
+		return 0, err
+	}
+	m.audit.FillUpdated(ctx, &entity)
+	update, err := m.buildUpdate(entity)
+	if err != nil {
+		return 0, err
+	}
+	result, err := m.collection.UpdateOne(ctx, filter, update)
+	err = translateError(err)
 	if NoError(err) {
-		return result.MatchedCount, err
+		return result.MatchedCount, AfterUpdate(ctx, &entity)
 	}
 	return 0, err
 }
 
 func (m *mongoDataAccess[E]) Patch(ctx context.Context, entity Entity) (int64, error) {
-	doc := buildPatch(entity)
-	idFilter := buildIdFilter(entity.GetId())
-	return unwrapPatch(m.collection.UpdateMany(ctx, idFilter, doc))
+	idFilter, err := m.buildIdFilter(ctx, entity.GetId())
+	if err != nil {
+		return 0, err
+	}
+	self := reflect.New(reflect.TypeOf(entity))
+	self.Elem().Set(reflect.ValueOf(entity))
+	if err = BeforeUpdate(ctx, self.Interface()); err != nil {
+		return 0, err
+	}
+	m.audit.FillUpdated(ctx, self.Interface())
+	doc := m.buildPatch(self.Elem().Interface())
+	cnt, err := unwrapPatch(m.collection.UpdateMany(ctx, idFilter, doc))
+	if err == nil {
+		err = AfterUpdate(ctx, self.Interface())
+	}
+	return cnt, err
 }
 
 func buildPatch(entity any) M {
@@ -271,6 +545,16 @@ func buildPatch(entity any) M {
 	return M{"$set": dst}
 }
 
+// buildPatch builds the patch of entity, which
+// never moves the documents to another tenant.
+func (m *mongoDataAccess[E]) buildPatch(entity any) M {
+	doc := buildPatch(entity)
+	if m.tenant != "" {
+		delete(doc["$set"].(M), m.tenant)
+	}
+	return doc
+}
+
 func flattenDoc(dst M, path string, value any) {
 	vType := reflect.TypeOf(value)
 	if vType.Kind() == reflect.Struct {
@@ -303,8 +587,15 @@ func readFieldName(field reflect.StructField) string {
 }
 
 func (m *mongoDataAccess[E]) PatchByQuery(ctx context.Context, entity E, query Query) (int64, error) {
-	doc := buildPatch(entity)
-	filter := buildFilter(query)
+	filter, err := m.buildFilter(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	if err = BeforeUpdate(ctx, &entity); err != nil {
+		return 0, err
+	}
+	m.audit.FillUpdated(ctx, &entity)
+	doc := m.buildPatch(entity)
 	if query.NeedPaging() {
 		IDs, err := m.doQueryIds(ctx, query, filter)
 		if err != nil {
@@ -312,12 +603,172 @@ func (m *mongoDataAccess[E]) PatchByQuery(ctx context.Context, entity E, query Q
 		}
 		filter = D{{MID, D{{"$in", IDs}}}}
 	}
-	return unwrapPatch(m.collection.UpdateMany(ctx, filter, doc))
+	cnt, err := unwrapPatch(m.collection.UpdateMany(ctx, filter, doc))
+	if err == nil {
+		err = AfterUpdate(ctx, &entity)
+	}
+	return cnt, err
 }
 
 func unwrapPatch(result *mongo.UpdateResult, err error) (int64, error) {
 	if NoError(err) {
 		return result.MatchedCount, err
 	}
-	return 0, err
+	return 0, translateError(err)
+}
+
+// translateError wraps the error of a duplicate key into a ConstraintError.
+func translateError(err error) error {
+	if err != nil && mongo.IsDuplicateKeyError(err) {
+		return &ConstraintError{Err: err}
+	}
+	return err
+}
+
+func (m *mongoDataAccess[E]) Upsert(ctx context.Context, entity E) (int64, error) {
+	return m.UpsertMulti(ctx, []E{entity})
+}
+
+// UpsertMulti inserts the entities prepared as by Create, and
+// updates the conflicting documents instead except the _id, the
+// audit fields of the creation and the soft-delete flag.
+func (m *mongoDataAccess[E]) UpsertMulti(ctx context.Context, entities []E) (int64, error) {
+	if len(entities) == 0 {
+		return 0, nil
+	}
+	models := make([]mongo.WriteModel, len(entities))
+	for i := range entities {
+		filter, update, err := m.prepareUpsert(ctx, &entities[i])
+		if err != nil {
+			return 0, err
+		}
+		models[i] = mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true)
+	}
+	result, err := m.collection.BulkWrite(ctx, models)
+	err = translateError(err)
+	if !NoError(err) {
+		return 0, err
+	}
+	for i := 0; err == nil && i < len(entities); i++ {
+		err = AfterCreate(ctx, &entities[i])
+	}
+	return result.MatchedCount + result.UpsertedCount, err
+}
+
+// prepareUpsert prepares the entity pointed by self as by Create,
+// and builds the filter and the update to upsert it.
+func (m *mongoDataAccess[E]) prepareUpsert(ctx context.Context, self *E) (D, M, error) {
+	if err := m.fillTenant(ctx, self); err != nil {
+		return nil, nil, err
+	}
+	if err := BeforeCreate(ctx, self); err != nil {
+		return nil, nil, err
+	}
+	m.audit.FillCreated(ctx, self)
+	if err := generateId(self); err != nil {
+		return nil, nil, err
+	}
+	filter, err := m.buildUpsertFilter(ctx, self)
+	if err != nil {
+		return nil, nil, err
+	}
+	update, err := m.buildUpsert(*self)
+	return filter, update, err
+}
+
+// generateId sets a new ObjectID to the entity pointed by self
+// without an _id, unless it is upserted by the ConflictColumns.
+func generateId[E MongoEntity](self *E) error {
+	if _, ok := any(*self).(UpsertEntity); ok {
+		return nil
+	}
+	doc, err := marshalDoc(*self)
+	if _, ok := doc[MID]; err != nil || ok {
+		return err
+	}
+	return (*self).SetId(self, NewObjectID())
+}
+
+// buildUpdate builds the update to replace the fields of the
+// document except the _id, the tenant and the audit fields of the creation.
+func (m *mongoDataAccess[E]) buildUpdate(entity E) (M, error) {
+	doc, err := marshalDoc(entity)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range append([]string{MID, m.tenant}, m.created...) {
+		delete(doc, key)
+	}
+	return M{"$set": doc}, nil
+}
+
+// buildUpsert builds the update of the upserted entity, which sets
+// the _id, the audit fields of the creation and the soft-delete flag
+// only on the insert to keep those of the existing document.
+func (m *mongoDataAccess[E]) buildUpsert(entity E) (M, error) {
+	doc, err := marshalDoc(entity)
+	if err != nil {
+		return nil, err
+	}
+	delete(doc, m.tenant)
+	onInsert := M{}
+	for _, key := range append([]string{MID, m.softDelete}, m.created...) {
+		if value, ok := doc[key]; ok {
+			onInsert[key] = value
+			delete(doc, key)
+		}
+	}
+	update := M{}
+	if len(doc) > 0 {
+		update["$set"] = doc
+	}
+	if len(onInsert) > 0 {
+		update["$setOnInsert"] = onInsert
+	}
+	return update, nil
+}
+
+// buildUpsertFilter stamps the tenant in ctx on the entity pointed
+// by self and matches the document in the tenant only.
+func (m *mongoDataAccess[E]) buildUpsertFilter(ctx context.Context, self *E) (D, error) {
+	if err := m.fillTenant(ctx, self); err != nil {
+		return nil, err
+	}
+	filter, err := buildUpsertFilter(*self)
+	if err != nil {
+		return nil, err
+	}
+	return m.filterTenant(ctx, filter)
+}
+
+// buildUpsertFilter matches the document by the ConflictColumns
+// of the entity, or by the _id when it is not an UpsertEntity.
+func buildUpsertFilter(entity any) (D, error) {
+	keys := []string{MID}
+	if ue, ok := entity.(UpsertEntity); ok {
+		keys = ue.ConflictColumns()
+	}
+	doc, err := marshalDoc(entity)
+	if err != nil {
+		return nil, err
+	}
+	filter := make(D, 0, len(keys))
+	for _, key := range keys {
+		value, ok := doc[key]
+		if !ok {
+			return nil, errors.New("missing value of conflict column: " + key)
+		}
+		filter = append(filter, E{key, value})
+	}
+	return filter, nil
+}
+
+// marshalDoc converts entity to the document stored in the collection.
+func marshalDoc(entity any) (M, error) {
+	data, err := bson.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	doc := M{}
+	return doc, bson.Unmarshal(data, &doc)
 }
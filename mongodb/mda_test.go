@@ -13,12 +13,15 @@ package mongodb
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	. "github.com/doytowin/goooqo/core"
 	log "github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestMongoDataAccess(t *testing.T) {
@@ -120,6 +123,33 @@ func TestMongoDataAccess(t *testing.T) {
 		}
 	})
 
+	t.Run("Support CursorPage", func(t *testing.T) {
+		inventoryQuery := InventoryQuery{PageQuery: PageQuery{Size: 2, Sort: "qty,desc", After: P("")}}
+
+		actual, err := inventoryDataAccess.CursorPage(ctx, inventoryQuery)
+		if !(err == nil && len(actual.List) == 2 && *actual.List[1].Qty == 75 && actual.Next != "") {
+			t.Fatalf("%s\n     Got: %v", err, actual)
+		}
+
+		inventoryQuery.After = &actual.Next
+		actual, err = inventoryDataAccess.CursorPage(ctx, inventoryQuery)
+		if !(err == nil && len(actual.List) == 2 && *actual.List[0].Qty == 50 && *actual.List[1].Qty == 45) {
+			t.Errorf("%s\n     Got: %v", err, actual)
+		}
+	})
+
+	t.Run("Support Iterate", func(t *testing.T) {
+		var qtyList []int
+		inventoryQuery := InventoryQuery{QtyGt: P(40), PageQuery: PageQuery{Sort: "qty"}}
+		err := inventoryDataAccess.Iterate(ctx, inventoryQuery, func(entity InventoryEntity) error {
+			qtyList = append(qtyList, *entity.Qty)
+			return nil
+		})
+		if !(err == nil && reflect.DeepEqual(qtyList, []int{45, 50, 75, 100})) {
+			t.Errorf("%s\n     Got: %v", err, qtyList)
+		}
+	})
+
 	t.Run("Support OR Query", func(t *testing.T) {
 		tc, _ := inventoryDataAccess.StartTransaction(ctx)
 		defer tc.Rollback()
@@ -148,7 +178,7 @@ func TestMongoDataAccess(t *testing.T) {
 			t.Errorf("%s\nExpected: %d\n     Got: %d", err, expect, actual)
 		}
 		inventory, err := inventoryDataAccess.Get(tc, "657bbb49675e5c32a2b8af73")
-		if !(err != nil && inventory == nil) {
+		if !(err == ErrNotFound && inventory == nil) {
 			t.Errorf("%s\nExpected: %v\n     Got: %v", err, nil, inventory)
 		}
 		log.Debugln(actual)
@@ -264,6 +294,32 @@ func TestMongoDataAccess(t *testing.T) {
 		log.Debugln(actual)
 	})
 
+	t.Run("Support Upsert", func(t *testing.T) {
+		tc, _ := inventoryDataAccess.StartTransaction(ctx)
+		defer tc.Rollback()
+
+		Id, _ := primitive.ObjectIDFromHex("657bbb49675e5c32a2b8af72")
+		newId := primitive.NewObjectID()
+		entities := []InventoryEntity{
+			{MongoId: NewMongoId(&Id), Item: P("journal"), Qty: P(50), Status: P("A")},
+			{MongoId: NewMongoId(&newId), Item: P("eraser"), Qty: P(20), Status: P("A")},
+			{Item: P("pencil"), Qty: P(30), Status: P("A")},
+		}
+		actual, err := inventoryDataAccess.UpsertMulti(tc, entities)
+		if !(err == nil && actual == 3 && entities[2].Id != nil) {
+			t.Errorf("%s\nExpected: %d\n     Got: %d", err, 3, actual)
+		}
+
+		cnt, err := inventoryDataAccess.Count(tc, InventoryQuery{})
+		if !(err == nil && cnt == int64(7)) {
+			t.Errorf("%s\nExpected: %d\n     Got: %d", err, 7, cnt)
+		}
+		inventory, err := inventoryDataAccess.Get(tc, Id)
+		if !(err == nil && *inventory.Qty == 50) {
+			t.Errorf("%s\nExpected: %d\n     Got: %v", err, 50, inventory)
+		}
+	})
+
 	t.Run("Support Patch", func(t *testing.T) {
 		tc, _ := inventoryDataAccess.StartTransaction(ctx)
 		defer tc.Rollback()
@@ -341,6 +397,98 @@ func assertEquals(t *testing.T, expect any, actual any) {
 	}
 }
 
+type ItemEntity struct {
+	InventoryEntity `bson:",inline"`
+}
+
+func (r ItemEntity) ConflictColumns() []string {
+	return []string{"item", "status"}
+}
+
+func Test_buildUpsertFilter(t *testing.T) {
+	Id, _ := primitive.ObjectIDFromHex("657bbb49675e5c32a2b8af72")
+	tests := []struct {
+		name   string
+		entity any
+		expect primitive.D
+		err    string
+	}{
+		{"Match by _id", InventoryEntity{MongoId: NewMongoId(&Id), Item: P("eraser")},
+			primitive.D{{"_id", Id}}, ""},
+		{"Match by ConflictColumns", ItemEntity{InventoryEntity{Item: P("eraser"), Status: P("A")}},
+			primitive.D{{"item", "eraser"}, {"status", "A"}}, ""},
+		{"Reject the missing _id", InventoryEntity{Item: P("eraser")},
+			nil, "missing value of conflict column: _id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildUpsertFilter(tt.entity)
+			if err != nil && err.Error() != tt.err {
+				t.Errorf("buildUpsertFilter() error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("buildUpsertFilter() = %v, want %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+type AuditEntity struct {
+	TrashEntity `bson:",inline"`
+	CreatedAt   *time.Time `bson:"createdAt,omitempty" createdAt:""`
+	UpdatedAt   *time.Time `bson:"updatedAt,omitempty" updatedAt:""`
+}
+
+func Test_buildUpsert(t *testing.T) {
+	entityType := reflect.TypeOf(AuditEntity{})
+	m := &mongoDataAccess[AuditEntity]{softDelete: "deleted", audit: BuildAuditFields(entityType)}
+	m.created = findCreatedFields(entityType, m.audit)
+
+	entity := AuditEntity{TrashEntity: TrashEntity{InventoryEntity: InventoryEntity{Item: P("eraser")}}}
+	filter, update, err := m.prepareUpsert(context.Background(), &entity)
+	if err != nil || entity.Id == nil || entity.CreatedAt == nil || entity.UpdatedAt == nil {
+		t.Fatalf("prepareUpsert() = %v, %v", entity, err)
+	}
+	if expect := (primitive.D{{"_id", *entity.Id}}); !reflect.DeepEqual(filter, expect) {
+		t.Errorf("prepareUpsert() filter = %v, want %v", filter, expect)
+	}
+	onInsert := update["$setOnInsert"].(primitive.M)
+	if _, ok := onInsert["_id"]; !ok || onInsert["createdAt"] == nil {
+		t.Errorf("prepareUpsert() $setOnInsert = %v, want _id and createdAt", onInsert)
+	}
+	set := update["$set"].(primitive.M)
+	if _, ok := set["createdAt"]; ok || set["item"] != "eraser" || set["updatedAt"] == nil {
+		t.Errorf("prepareUpsert() $set = %v, want item and updatedAt only", set)
+	}
+
+	entity.Deleted = P(false)
+	update, _ = m.buildUpsert(entity)
+	if onInsert = update["$setOnInsert"].(primitive.M); onInsert["deleted"] != false {
+		t.Errorf("buildUpsert() $setOnInsert = %v, want deleted", onInsert)
+	}
+
+	update, _ = m.buildUpdate(entity)
+	if set = update["$set"].(primitive.M); set["createdAt"] != nil || set["_id"] != nil || set["updatedAt"] == nil {
+		t.Errorf("buildUpdate() $set = %v, want no _id or createdAt", set)
+	}
+
+	item := ItemEntity{InventoryEntity{Item: P("eraser"), Status: P("A")}}
+	if err = generateId(&item); err != nil || item.Id != nil {
+		t.Errorf("generateId() = %v, %v, want no _id for ConflictColumns", item.Id, err)
+	}
+}
+
+func Test_buildColumns(t *testing.T) {
+	columns := buildColumns(reflect.TypeOf(InventoryEntity{}))
+	expect := []string{"_id", "item", "size", "qty", "status"}
+	if !reflect.DeepEqual(columns, expect) {
+		t.Errorf("buildColumns() = %v, want %v", columns, expect)
+	}
+	if err := ValidateSort(InventoryQuery{PageQuery: PageQuery{Sort: "qty;$where"}}, columns); !errors.Is(err, ErrInvalidSort) {
+		t.Errorf("ValidateSort() = %v, want %v", err, ErrInvalidSort)
+	}
+}
+
 func Test_createIndexModel(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -357,3 +505,85 @@ func Test_createIndexModel(t *testing.T) {
 		})
 	}
 }
+
+type TrashEntity struct {
+	InventoryEntity `bson:",inline"`
+	Deleted         *bool `bson:"deleted,omitempty" softdelete:""`
+}
+
+func Test_filterDeleted(t *testing.T) {
+	m := &mongoDataAccess[TrashEntity]{softDelete: findSoftDelete(reflect.TypeOf(TrashEntity{}))}
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		expect primitive.D
+	}{
+		{"Filter out the deleted", context.Background(),
+			primitive.D{{"item", "eraser"}, {"deleted", primitive.D{{"$ne", true}}}}},
+		{"Read with the deleted", WithDeleted(context.Background()),
+			primitive.D{{"item", "eraser"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.filterDeleted(tt.ctx, primitive.D{{"item", "eraser"}}); !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("filterDeleted() = %v, want %v", got, tt.expect)
+			}
+		})
+	}
+	filter, err := m.buildFilter(context.Background(), InventoryQuery{})
+	if expect := (primitive.D{{"deleted", primitive.D{{"$ne", true}}}}); err != nil || !reflect.DeepEqual(filter, expect) {
+		t.Errorf("buildFilter() = %v, %v, want %v", filter, err, expect)
+	}
+	Id, _ := primitive.ObjectIDFromHex("657bbb49675e5c32a2b8af72")
+	filter, err = m.buildIdFilter(context.Background(), Id)
+	if expect := (primitive.D{{"_id", Id}, {"deleted", primitive.D{{"$ne", true}}}}); err != nil || !reflect.DeepEqual(filter, expect) {
+		t.Errorf("buildIdFilter() = %v, %v, want %v", filter, err, expect)
+	}
+	if name := findSoftDelete(reflect.TypeOf(InventoryEntity{})); name != "" {
+		t.Errorf("findSoftDelete() = %v, want empty", name)
+	}
+}
+
+type TenantEntity struct {
+	InventoryEntity `bson:",inline"`
+	TenantId        *int `bson:"tenantId,omitempty" tenant:""`
+}
+
+func Test_filterTenant(t *testing.T) {
+	m := &mongoDataAccess[TenantEntity]{tenant: "tenantId", tenantField: "TenantId"}
+	ctx := context.Background()
+	if _, err := m.filterTenant(ctx, primitive.D{}); err != ErrTenantRequired {
+		t.Errorf("filterTenant() error = %v, want %v", err, ErrTenantRequired)
+	}
+	ctx = WithTenantId(ctx, 3)
+	got, err := m.filterTenant(ctx, primitive.D{{"item", "eraser"}})
+	expect := primitive.D{{"item", "eraser"}, {"tenantId", 3}}
+	if err != nil || !reflect.DeepEqual(got, expect) {
+		t.Errorf("filterTenant() = %v, %v, want %v", got, err, expect)
+	}
+	entity := TenantEntity{TenantId: P(5)}
+	if err = m.fillTenant(ctx, &entity); err != nil || *entity.TenantId != 3 {
+		t.Errorf("fillTenant() = %v, %v", *entity.TenantId, err)
+	}
+	doc := m.buildPatch(entity)
+	if _, ok := doc["$set"].(primitive.M)["tenantId"]; ok {
+		t.Errorf("buildPatch() = %v, want no tenantId", doc)
+	}
+	Id, _ := primitive.ObjectIDFromHex("657bbb49675e5c32a2b8af72")
+	entity = TenantEntity{InventoryEntity: InventoryEntity{MongoId: NewMongoId(&Id)}, TenantId: P(5)}
+	filter, err := m.buildUpsertFilter(ctx, &entity)
+	expect = primitive.D{{"_id", Id}, {"tenantId", 3}}
+	if err != nil || !reflect.DeepEqual(filter, expect) || *entity.TenantId != 3 {
+		t.Errorf("buildUpsertFilter() = %v, %v, want %v", filter, err, expect)
+	}
+}
+
+func Test_translateError(t *testing.T) {
+	duplicate := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "E11000 duplicate key error"}}}
+	if err := translateError(duplicate); !errors.Is(err, ErrConstraintViolation) {
+		t.Errorf("translateError() = %v, want %v", err, ErrConstraintViolation)
+	}
+	if err := translateError(mongo.ErrNoDocuments); err != mongo.ErrNoDocuments {
+		t.Errorf("translateError() = %v, want %v", err, mongo.ErrNoDocuments)
+	}
+}
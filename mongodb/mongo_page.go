@@ -11,10 +11,33 @@
 package mongodb
 
 import (
+	"errors"
+	"time"
+
 	"github.com/doytowin/goooqo/core"
+	"go.mongodb.org/mongo-driver/bson"
 	. "go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// register the BSON types of the cursor values, which
+// are compared with the same types in the keyset filter.
+func init() {
+	core.RegisterCursorType("oid", func(value any) (string, bool) {
+		ID, ok := value.(ObjectID)
+		return ID.Hex(), ok
+	}, func(text string) (any, error) {
+		return ObjectIDFromHex(text)
+	})
+	core.RegisterCursorType("date", func(value any) (string, bool) {
+		date, ok := value.(DateTime)
+		return date.Time().UTC().Format(time.RFC3339Nano), ok
+	}, func(text string) (any, error) {
+		t, err := time.Parse(time.RFC3339Nano, text)
+		return NewDateTimeFromTime(t), err
+	})
+}
+
 func buildSort(sort string) D {
 	submatch := core.SortRgx.FindAllStringSubmatch(sort, -1)
 	result := make(D, len(submatch))
@@ -27,3 +50,63 @@ func buildSort(sort string) D {
 	}
 	return result
 }
+
+func buildCursorOpt(query core.Query) *options.FindOptions {
+	columns := core.BuildCursorColumns(query.GetSort(), MID)
+	sort := make(D, len(columns))
+	for i, column := range columns {
+		sort[i] = E{column.Name, core.Ternary(column.Desc, -1, 1)}
+	}
+	return &options.FindOptions{Limit: PInt64(query.GetPageSize()), Sort: sort}
+}
+
+// buildCursorFilter appends the condition of the documents after
+// the cursor to filter, such as `{$or: [{qty: {$lt: 20}},
+// {qty: 20, _id: {$gt: ObjectId(...)}}]}` for the sort `qty,desc`.
+func buildCursorFilter(filter D, sort string, after string) (D, error) {
+	if after == "" {
+		return filter, nil
+	}
+	columns := core.BuildCursorColumns(sort, MID)
+	values, err := core.DecodeCursor(after, len(columns))
+	if err != nil {
+		return nil, err
+	}
+	groups := make(A, len(columns))
+	for i, column := range columns {
+		group := make(D, 0, i+1)
+		for j := 0; j < i; j++ {
+			group = append(group, E{columns[j].Name, values[j]})
+		}
+		op := core.Ternary(column.Desc, "$lt", "$gt")
+		group = append(group, E{column.Name, D{{op, values[i]}}})
+		groups[i] = group
+	}
+	keyset := D{{"$or", groups}}
+	if len(filter) == 0 {
+		return keyset, nil
+	}
+	return D{{"$and", A{filter, keyset}}}, nil
+}
+
+// buildCursor encodes the values of the cursor columns of entity.
+func buildCursor(entity any, sort string) (string, error) {
+	data, err := bson.Marshal(entity)
+	if err != nil {
+		return "", err
+	}
+	doc := M{}
+	if err = bson.Unmarshal(data, &doc); err != nil {
+		return "", err
+	}
+	columns := core.BuildCursorColumns(sort, MID)
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		value, ok := doc[column.Name]
+		if !ok {
+			return "", errors.New("unknown cursor column: " + column.Name)
+		}
+		values[i] = value
+	}
+	return core.EncodeCursor(values)
+}
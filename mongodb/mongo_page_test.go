@@ -13,7 +13,9 @@ package mongodb
 import (
 	"reflect"
 	"testing"
+	"time"
 
+	. "github.com/doytowin/goooqo/core"
 	. "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -36,3 +38,54 @@ func Test_buildSort(t *testing.T) {
 		})
 	}
 }
+
+func Test_buildCursor(t *testing.T) {
+	Id, _ := ObjectIDFromHex("657bbb49675e5c32a2b8af72")
+	entity := InventoryEntity{MongoId: NewMongoId(&Id), Item: P("journal"), Qty: P(25)}
+
+	t.Run("Encode and decode the cursor by the sort columns", func(t *testing.T) {
+		after, err := buildCursor(entity, "qty,desc;item")
+		if err != nil {
+			t.Fatal(err)
+		}
+		filter, err := buildCursorFilter(D{{"status", "A"}}, "qty,desc;item", after)
+		expect := D{{"$and", A{D{{"status", "A"}}, D{{"$or", A{
+			D{{"qty", D{{"$lt", int64(25)}}}},
+			D{{"qty", int64(25)}, {"item", D{{"$gt", "journal"}}}},
+			D{{"qty", int64(25)}, {"item", "journal"}, {"_id", D{{"$gt", Id}}}},
+		}}}}}}
+		if !(err == nil && reflect.DeepEqual(filter, expect)) {
+			t.Errorf("buildCursorFilter() = %v, want %v, %v", filter, expect, err)
+		}
+	})
+
+	t.Run("Compare the dates with the dates after the cursor", func(t *testing.T) {
+		date := NewDateTimeFromTime(time.Date(2026, 1, 2, 3, 4, 5, 6000000, time.UTC))
+		after, err := buildCursor(D{{"_id", Id}, {"created", date}}, "created,desc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		filter, err := buildCursorFilter(D{}, "created,desc", after)
+		expect := D{{"$or", A{
+			D{{"created", D{{"$lt", date}}}},
+			D{{"created", date}, {"_id", D{{"$gt", Id}}}},
+		}}}
+		if !(err == nil && reflect.DeepEqual(filter, expect)) {
+			t.Errorf("buildCursorFilter() = %v, want %v, %v", filter, expect, err)
+		}
+	})
+
+	t.Run("Keep the filter for the first page", func(t *testing.T) {
+		filter, err := buildCursorFilter(D{}, "item", "")
+		if !(err == nil && len(filter) == 0) {
+			t.Errorf("buildCursorFilter() = %v, %v", filter, err)
+		}
+	})
+
+	t.Run("Sort by _id without skip", func(t *testing.T) {
+		opt := buildPageOpt(InventoryQuery{PageQuery: PageQuery{Page: 3, Size: 5, Sort: "item", After: P("")}})
+		if !(*opt.Limit == 5 && opt.Skip == nil && reflect.DeepEqual(opt.Sort, D{{"item", 1}, {"_id", 1}})) {
+			t.Errorf("buildPageOpt() = %v", opt)
+		}
+	})
+}
@@ -12,9 +12,12 @@ package mongodb
 
 import (
 	"context"
+	"database/sql"
 
 	. "github.com/doytowin/goooqo/core"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
 )
 
 type mongoTransactionManager struct {
@@ -29,31 +32,31 @@ func (tm *mongoTransactionManager) GetClient() any {
 	return tm.client
 }
 
-func (tm *mongoTransactionManager) StartTransaction(ctx context.Context) (TransactionContext, error) {
-	ssnCtx, err := tm.resolveCtx(ctx)
-	if NoError(err) && !ssnCtx.active {
-		err = ssnCtx.StartTransaction()
-		if NoError(err) {
-			ssnCtx.active = true
-		}
-	}
-	return ssnCtx, err
+func (tm *mongoTransactionManager) StartTransaction(ctx context.Context, opts ...TxOption) (TransactionContext, error) {
+	tc, _, err := tm.startTransaction(ctx, BuildTxOptions(opts...))
+	return tc, err
 }
 
-func (tm *mongoTransactionManager) SubmitTransaction(ctx context.Context, callback func(tc TransactionContext) error) error {
-	tc, err := tm.StartTransaction(ctx)
-	if NoError(err) {
-		err = TransactionCallback(tc, callback)
+// startTransaction starts the transaction by the options, and reports
+// whether the transaction in ctx is joined. The nested transaction is
+// not supported since MongoDB has no savepoint.
+func (tm *mongoTransactionManager) startTransaction(ctx context.Context, txOpts TxOptions) (TransactionContext, bool, error) {
+	ssnCtx, ok := ctx.Value(TxKey{}).(*mongoTransactionContext)
+	if ok && ssnCtx.active {
+		switch txOpts.Propagation {
+		case PropagationRequired, PropagationMandatory:
+			return ssnCtx, true, nil
+		case PropagationNested:
+			return nil, false, ErrSavePointNotSupported
+		}
+		ok = false
+	} else if txOpts.Propagation == PropagationMandatory {
+		return nil, false, ErrNoTransaction
 	}
-	return err
-}
-
-func (tm *mongoTransactionManager) resolveCtx(ctx context.Context) (*mongoTransactionContext, error) {
-	ssnCtx, ok := ctx.(*mongoTransactionContext)
 	if !ok {
 		sess, err := tm.client.StartSession()
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 		ssnCtx = &mongoTransactionContext{
 			SessionContext: mongo.NewSessionContext(ctx, sess),
@@ -61,13 +64,65 @@ func (tm *mongoTransactionManager) resolveCtx(ctx context.Context) (*mongoTransa
 			parent:         ctx,
 		}
 	}
-	return ssnCtx, nil
+	err := ssnCtx.StartTransaction(buildTransactionOptions(txOpts))
+	if NoError(err) {
+		ssnCtx.active = true
+	}
+	return ssnCtx, false, err
+}
+
+// buildTransactionOptions reads from the snapshot for the isolation
+// levels from LevelRepeatableRead. The read-only mode is ignored
+// since a transaction of MongoDB always reads from the primary.
+func buildTransactionOptions(txOpts TxOptions) *options.TransactionOptions {
+	opts := options.Transaction()
+	if txOpts.Isolation >= sql.LevelRepeatableRead {
+		opts.SetReadConcern(readconcern.Snapshot())
+	}
+	return opts
+}
+
+// SubmitTransaction commits the transaction started by the options
+// when callback succeeds, or aborts it otherwise. A joined
+// transaction is left to its owner to commit or abort.
+func (tm *mongoTransactionManager) SubmitTransaction(ctx context.Context, callback func(tc TransactionContext) error, opts ...TxOption) error {
+	tc, joined, err := tm.startTransaction(ctx, BuildTxOptions(opts...))
+	if !NoError(err) {
+		return err
+	}
+	if joined {
+		return callback(tc)
+	}
+	return TransactionCallback(tc, callback)
 }
 
 type mongoTransactionContext struct {
 	mongo.SessionContext
-	active bool
-	parent context.Context
+	active    bool
+	parent    context.Context
+	callbacks []func()
+}
+
+// Value returns the transaction context itself for TxKey, so the
+// transaction is still found after ctx is wrapped by context.WithValue.
+func (t *mongoTransactionContext) Value(key any) any {
+	if key == (TxKey{}) {
+		return t
+	}
+	return t.SessionContext.Value(key)
+}
+
+func (t *mongoTransactionContext) AfterCompletion(callback func()) {
+	t.callbacks = append(t.callbacks, callback)
+}
+
+// complete invokes the callbacks registered by AfterCompletion once.
+func (t *mongoTransactionContext) complete() {
+	callbacks := t.callbacks
+	t.callbacks = nil
+	for _, callback := range callbacks {
+		callback()
+	}
 }
 
 func (t *mongoTransactionContext) Parent() context.Context {
@@ -75,17 +130,19 @@ func (t *mongoTransactionContext) Parent() context.Context {
 }
 
 func (t *mongoTransactionContext) Commit() error {
+	defer t.complete()
 	return t.CommitTransaction(t.SessionContext)
 }
 
 func (t *mongoTransactionContext) Rollback() error {
+	defer t.complete()
 	return t.AbortTransaction(t.SessionContext)
 }
 
-func (t *mongoTransactionContext) SavePoint(name string) error {
-	panic("not implemented")
+func (t *mongoTransactionContext) SavePoint(string) error {
+	return ErrSavePointNotSupported
 }
 
-func (t *mongoTransactionContext) RollbackTo(name string) error {
-	panic("not implemented")
+func (t *mongoTransactionContext) RollbackTo(string) error {
+	return ErrSavePointNotSupported
 }
@@ -0,0 +1,46 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/doytowin/goooqo/core"
+)
+
+func Test_startTransaction(t *testing.T) {
+	tm := &mongoTransactionManager{}
+	tc := &mongoTransactionContext{active: true}
+	wrapped := WithTenantId(WithUserId(tc, 1), 2)
+
+	t.Run("Join the transaction in the wrapped ctx", func(t *testing.T) {
+		for _, propagation := range []Propagation{PropagationRequired, PropagationMandatory} {
+			actual, joined, err := tm.startTransaction(wrapped, TxOptions{Propagation: propagation})
+			if err != nil || !joined || actual != tc {
+				t.Errorf("startTransaction() = %v, %v, %v", actual, joined, err)
+			}
+		}
+	})
+
+	t.Run("Reject the nested transaction in the wrapped ctx", func(t *testing.T) {
+		if _, _, err := tm.startTransaction(wrapped, TxOptions{Propagation: PropagationNested}); err != ErrSavePointNotSupported {
+			t.Errorf("startTransaction() error = %v, want %v", err, ErrSavePointNotSupported)
+		}
+	})
+
+	t.Run("Require the transaction for PropagationMandatory", func(t *testing.T) {
+		ctx := WithTenantId(context.Background(), 2)
+		if _, _, err := tm.startTransaction(ctx, TxOptions{Propagation: PropagationMandatory}); err != ErrNoTransaction {
+			t.Errorf("startTransaction() error = %v, want %v", err, ErrNoTransaction)
+		}
+	})
+}
@@ -12,6 +12,7 @@ package rdb
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	. "github.com/doytowin/goooqo/core"
@@ -23,6 +24,7 @@ type UniqueKey struct {
 }
 
 type AssociationSqlBuilder struct {
+	dialect          DbDialect
 	tableName        string
 	k1Column         string
 	k2Column         string
@@ -33,17 +35,28 @@ type AssociationSqlBuilder struct {
 	SelectK2ColumnByK1Id string
 	DeleteByK1           string
 	DeleteByK2           string
-	InsertSQL            string
-	DeleteSQL            string
-	CountSQL             string
+	// Deprecated: InsertSQL only fits the dialects supporting
+	// INSERT OR IGNORE like SQLite. Use BuildInsert instead.
+	InsertSQL string
+	// Deprecated: DeleteSQL only fits the dialects supporting
+	// the row value constructor in IN. Use BuildDelete instead.
+	DeleteSQL string
+	// Deprecated: CountSQL only fits the dialects supporting
+	// the row value constructor in IN. Use BuildCount instead.
+	CountSQL string
 }
 
 func NewAssociationSqlBuilder(e1, e2 string) *AssociationSqlBuilder {
-	tableName := fmt.Sprintf("a_%s_and_%s", e1, e2)
-	k1Column := fmt.Sprintf("%s_id", e1)
-	k2Column := fmt.Sprintf("%s_id", e2)
+	return newAssociationSqlBuilder(Dialect, e1, e2)
+}
+
+func newAssociationSqlBuilder(dialect DbDialect, e1, e2 string) *AssociationSqlBuilder {
+	tableName := dialect.Quote(fmt.Sprintf("a_%s_and_%s", e1, e2))
+	k1Column := dialect.Quote(fmt.Sprintf("%s_id", e1))
+	k2Column := dialect.Quote(fmt.Sprintf("%s_id", e2))
 
 	return &AssociationSqlBuilder{
+		dialect:   dialect,
 		tableName: tableName,
 		k1Column:  k1Column,
 		k2Column:  k2Column,
@@ -59,58 +72,46 @@ func NewAssociationSqlBuilder(e1, e2 string) *AssociationSqlBuilder {
 }
 
 func (b *AssociationSqlBuilder) BuildInsert(keys []UniqueKey) (string, []any) {
-	sql := b.InsertSQL
-	args := make([]any, 0)
-	for i, key := range keys {
-		if i > 0 {
-			sql += ", "
-		}
-		sql += "(?, ?)"
+	columns := []string{b.k1Column, b.k2Column}
+	sql := b.dialect.BuildInsertIgnore(b.tableName, columns, columns, len(keys))
+	args := make([]any, 0, 2*len(keys))
+	for _, key := range keys {
 		args = append(args, key.K1, key.K2)
 	}
 	return sql, args
 }
 
 func (b *AssociationSqlBuilder) BuildDelete(keys []UniqueKey) (string, []any) {
-	sql := b.DeleteSQL
-	args := make([]any, 0)
-	for i, key := range keys {
-		if i > 0 {
-			sql += ", "
-		}
-		sql += "(?, ?)"
-		args = append(args, key.K1, key.K2)
-	}
-	sql += ")"
-	return sql, args
+	return "DELETE FROM " + b.tableName + " WHERE " + b.buildKeysIn(len(keys)), buildKeyArgs(keys)
 }
 
 func (b *AssociationSqlBuilder) BuildCount(keys []UniqueKey) (string, []any) {
-	sql := b.CountSQL
-	args := make([]any, 0)
-	for i, key := range keys {
-		if i > 0 {
-			sql += ", "
-		}
-		sql += "(?, ?)"
+	return "SELECT count(*) FROM " + b.tableName + " WHERE " + b.buildKeysIn(len(keys)), buildKeyArgs(keys)
+}
+
+// buildKeysIn builds the condition matching
+// the k1 and k2 columns against `rows` keys.
+func (b *AssociationSqlBuilder) buildKeysIn(rows int) string {
+	return b.dialect.BuildRowIn([]string{b.k1Column, b.k2Column}, rows)
+}
+
+func buildKeyArgs(keys []UniqueKey) []any {
+	args := make([]any, 0, 2*len(keys))
+	for _, key := range keys {
 		args = append(args, key.K1, key.K2)
 	}
-	sql += ")"
-	return sql, args
+	return args
 }
 
 func (b *AssociationSqlBuilder) WithCreateUserColumn(column string) {
-	b.createUserColumn = column
+	b.createUserColumn = b.dialect.Quote(column)
 }
 
 func (b *AssociationSqlBuilder) BuildInsertWithUser(keys []UniqueKey, userId int) (string, []any) {
-	sql := "INSERT OR IGNORE INTO " + b.tableName + " (" + b.k1Column + ", " + b.k2Column + ", " + b.createUserColumn + ") VALUES "
-	args := make([]any, 0)
-	for i, key := range keys {
-		if i > 0 {
-			sql += ", "
-		}
-		sql += "(?, ?, ?)"
+	columns := []string{b.k1Column, b.k2Column, b.createUserColumn}
+	sql := b.dialect.BuildInsertIgnore(b.tableName, columns, columns[:2], len(keys))
+	args := make([]any, 0, 3*len(keys))
+	for _, key := range keys {
 		args = append(args, key.K1, key.K2, userId)
 	}
 	return sql, args
@@ -123,29 +124,43 @@ type RdbAssociationService struct {
 }
 
 func NewRdbAssociationService(tm TransactionManager, e1, e2, createUserColumn string) *RdbAssociationService {
-	builder := *NewAssociationSqlBuilder(e1, e2)
+	conn := tm.GetClient().(Connection)
+	builder := *newAssociationSqlBuilder(resolveDialect(tm, conn), e1, e2)
 	builder.WithCreateUserColumn(createUserColumn)
 	return &RdbAssociationService{
 		builder: builder,
 		tm:      tm,
-		conn:    tm.GetClient().(Connection),
+		conn:    conn,
 	}
 }
 
 func (s *RdbAssociationService) getConn(ctx context.Context) Connection {
-	if tc, ok := ctx.(*rdbTransactionContext); ok {
+	if tc, ok := readTransactionContext(ctx); ok {
 		return tc.tx
 	}
 	return s.conn
 }
 
+func (s *RdbAssociationService) exec(ctx context.Context, sqlStr string, args ...any) (int64, error) {
+	cnt, err := parse(s.getConn(ctx).ExecContext(ctx, s.builder.dialect.ResolvePlaceholders(sqlStr), args...))
+	return cnt, translateError(s.builder.dialect, err)
+}
+
+func (s *RdbAssociationService) query(ctx context.Context, sqlStr string, args ...any) (*sql.Rows, error) {
+	return s.getConn(ctx).QueryContext(ctx, s.builder.dialect.ResolvePlaceholders(sqlStr), args...)
+}
+
+func (s *RdbAssociationService) queryRow(ctx context.Context, sqlStr string, args ...any) *sql.Row {
+	return s.getConn(ctx).QueryRowContext(ctx, s.builder.dialect.ResolvePlaceholders(sqlStr), args...)
+}
+
 func (s *RdbAssociationService) Associate(ctx context.Context, k1 any, k2 any) (int64, error) {
-	sql := s.builder.InsertSQL + "(?, ?)"
-	return parse(s.getConn(ctx).ExecContext(ctx, sql, k1, k2))
+	sql, args := s.builder.BuildInsert([]UniqueKey{{K1: k1, K2: k2}})
+	return s.exec(ctx, sql, args...)
 }
 
 func (s *RdbAssociationService) QueryK1ByK2(ctx context.Context, k2 int) ([]int64, error) {
-	rows, err := s.getConn(ctx).QueryContext(ctx, s.builder.SelectK1ColumnByK2Id, k2)
+	rows, err := s.query(ctx, s.builder.SelectK1ColumnByK2Id, k2)
 	if err != nil {
 		return nil, err
 	}
@@ -163,7 +178,7 @@ func (s *RdbAssociationService) QueryK1ByK2(ctx context.Context, k2 int) ([]int6
 }
 
 func (s *RdbAssociationService) QueryK2ByK1(ctx context.Context, k1 int64) ([]int, error) {
-	rows, err := s.getConn(ctx).QueryContext(ctx, s.builder.SelectK2ColumnByK1Id, k1)
+	rows, err := s.query(ctx, s.builder.SelectK2ColumnByK1Id, k1)
 	if err != nil {
 		return nil, err
 	}
@@ -181,11 +196,11 @@ func (s *RdbAssociationService) QueryK2ByK1(ctx context.Context, k1 int64) ([]in
 }
 
 func (s *RdbAssociationService) DeleteByK1(ctx context.Context, k1 any) (int64, error) {
-	return parse(s.getConn(ctx).ExecContext(ctx, s.builder.DeleteByK1, k1))
+	return s.exec(ctx, s.builder.DeleteByK1, k1)
 }
 
 func (s *RdbAssociationService) DeleteByK2(ctx context.Context, k2 any) (int64, error) {
-	return parse(s.getConn(ctx).ExecContext(ctx, s.builder.DeleteByK2, k2))
+	return s.exec(ctx, s.builder.DeleteByK2, k2)
 }
 
 func (s *RdbAssociationService) ReassociateForK1(ctx context.Context, k1 int64, k2List []int) (int64, error) {
@@ -196,16 +211,12 @@ func (s *RdbAssociationService) ReassociateForK1(ctx context.Context, k1 int64,
 	if len(k2List) == 0 {
 		return 0, nil
 	}
-	sql := s.builder.InsertSQL
-	args := make([]any, 0)
+	keys := make([]UniqueKey, len(k2List))
 	for i, k2 := range k2List {
-		if i > 0 {
-			sql += ", "
-		}
-		sql += "(?, ?)"
-		args = append(args, k1, k2)
+		keys[i] = UniqueKey{K1: k1, K2: k2}
 	}
-	return parse(s.getConn(ctx).ExecContext(ctx, sql, args...))
+	sql, args := s.builder.BuildInsert(keys)
+	return s.exec(ctx, sql, args...)
 }
 
 func (s *RdbAssociationService) ReassociateForK2(ctx context.Context, k2 any, k1List []int64) (int64, error) {
@@ -216,28 +227,24 @@ func (s *RdbAssociationService) ReassociateForK2(ctx context.Context, k2 any, k1
 	if len(k1List) == 0 {
 		return 0, nil
 	}
-	sql := s.builder.InsertSQL
-	args := make([]any, 0)
+	keys := make([]UniqueKey, len(k1List))
 	for i, k1 := range k1List {
-		if i > 0 {
-			sql += ", "
-		}
-		sql += "(?, ?)"
-		args = append(args, k1, k2)
+		keys[i] = UniqueKey{K1: k1, K2: k2}
 	}
-	return parse(s.getConn(ctx).ExecContext(ctx, sql, args...))
+	sql, args := s.builder.BuildInsert(keys)
+	return s.exec(ctx, sql, args...)
 }
 
 func (s *RdbAssociationService) Count(ctx context.Context, keys []UniqueKey) (int64, error) {
 	sql, args := s.builder.BuildCount(keys)
 	var count int64
-	err := s.getConn(ctx).QueryRowContext(ctx, sql, args...).Scan(&count)
+	err := s.queryRow(ctx, sql, args...).Scan(&count)
 	return count, err
 }
 
 func (s *RdbAssociationService) Dissociate(ctx context.Context, k1 any, k2 any) (int64, error) {
 	sql := "DELETE FROM " + s.builder.tableName + " WHERE " + s.builder.k1Column + " = ? AND " + s.builder.k2Column + " = ?"
-	return parse(s.getConn(ctx).ExecContext(ctx, sql, k1, k2))
+	return s.exec(ctx, sql, k1, k2)
 }
 
 func (s *RdbAssociationService) BuildUniqueKeys(k1 any, k2List []any) []UniqueKey {
@@ -257,6 +264,6 @@ func (s *RdbAssociationService) BuildUniqueKeys(k1 any, k2List []any) []UniqueKe
 func (s *RdbAssociationService) Exists(ctx context.Context, k1 any, k2 any) (bool, error) {
 	sql := "SELECT COUNT(*) FROM " + s.builder.tableName + " WHERE " + s.builder.k1Column + " = ? AND " + s.builder.k2Column + " = ?"
 	var count int64
-	err := s.getConn(ctx).QueryRowContext(ctx, sql, k1, k2).Scan(&count)
+	err := s.queryRow(ctx, sql, k1, k2).Scan(&count)
 	return count > 0, err
 }
@@ -37,6 +37,10 @@ func TestAssociationSqlBuilder(t *testing.T) {
 		assert.Equal(t, "DELETE FROM a_user_and_role WHERE role_id = ?", builder.DeleteByK2)
 	})
 
+	t.Run("testInsertSQL", func(t *testing.T) {
+		assert.Equal(t, "INSERT OR IGNORE INTO a_user_and_role (user_id, role_id) VALUES ", builder.InsertSQL)
+	})
+
 	keys := []UniqueKey{{K1: 1, K2: 1}, {K1: 2, K2: 3}}
 
 	t.Run("testInsert", func(t *testing.T) {
@@ -57,6 +61,15 @@ func TestAssociationSqlBuilder(t *testing.T) {
 		assert.Equal(t, []any{1, 1, 2, 3}, args)
 	})
 
+	t.Run("Expand the keys by SQL Server", func(t *testing.T) {
+		builder := newAssociationSqlBuilder(&SQLServerDialect{}, "user", "role")
+		sql, args := builder.BuildDelete(keys)
+		assert.Equal(t, "DELETE FROM [a_user_and_role] WHERE ([user_id] = ? AND [role_id] = ?) OR ([user_id] = ? AND [role_id] = ?)", sql)
+		assert.Equal(t, []any{1, 1, 2, 3}, args)
+		sql, _ = builder.BuildCount(keys[:1])
+		assert.Equal(t, "SELECT count(*) FROM [a_user_and_role] WHERE ([user_id] = ? AND [role_id] = ?)", sql)
+	})
+
 	t.Run("testInsertWithUser", func(t *testing.T) {
 		builder.WithCreateUserColumn("create_user_id")
 		sql, args := builder.BuildInsertWithUser(keys, 1)
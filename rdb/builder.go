@@ -15,8 +15,10 @@ import (
 	"strings"
 )
 
+// QueryBuilder builds the conditions of a query by d,
+// the dialect of the DataAccess running the query.
 type QueryBuilder interface {
-	BuildConditions() ([]string, []any)
+	BuildConditions(d DbDialect) ([]string, []any)
 }
 
 type EntityMapper interface {
@@ -28,15 +30,33 @@ func isValidValue(value reflect.Value) bool {
 }
 
 func BuildWhereClause(query any) (string, []any) {
-	return BuildConditions(query, " WHERE ", " AND ", "")
+	return buildWhereClause(Dialect, query)
 }
 
-func BuildConditions(query any, prefix string, delimiter string, suffix string) (a string, args []any) {
+func BuildConditions(query any, prefix string, delimiter string, suffix string) (string, []any) {
+	return buildConditionsBy(Dialect, query, prefix, delimiter, suffix)
+}
+
+// BuildWhereClauseBy builds the WHERE clause of query by d.
+func BuildWhereClauseBy(d DbDialect, query any) (string, []any) {
+	return buildWhereClause(d, query)
+}
+
+// BuildConditionsBy joins the conditions of query built by d.
+func BuildConditionsBy(d DbDialect, query any, prefix string, delimiter string, suffix string) (string, []any) {
+	return buildConditionsBy(d, query, prefix, delimiter, suffix)
+}
+
+func buildWhereClause(d DbDialect, query any) (string, []any) {
+	return buildConditionsBy(d, query, " WHERE ", " AND ", "")
+}
+
+func buildConditionsBy(d DbDialect, query any, prefix string, delimiter string, suffix string) (a string, args []any) {
 	var conditions []string
 	if qb, ok := query.(QueryBuilder); ok {
-		conditions, args = qb.BuildConditions()
+		conditions, args = qb.BuildConditions(d)
 	} else {
-		conditions, args = buildConditions(query)
+		conditions, args = buildConditions(d, query)
 	}
 	if len(conditions) == 0 {
 		return "", []any{}
@@ -44,7 +64,7 @@ func BuildConditions(query any, prefix string, delimiter string, suffix string)
 	return prefix + strings.Join(conditions, delimiter) + suffix, args
 }
 
-func buildConditions(query any) ([]string, []any) {
+func buildConditions(d DbDialect, query any) ([]string, []any) {
 	rtype := reflect.TypeOf(query)
 	rvalue := reflect.ValueOf(query)
 	if rtype.Kind() == reflect.Pointer {
@@ -58,11 +78,11 @@ func buildConditions(query any) ([]string, []any) {
 	for i := 0; i < rtype.NumField(); i++ {
 		field := rtype.Field(i)
 		fpKey := buildFpKey(rtype, field)
-		processor := fpMap[fpKey]
+		processor := loadFieldProcessor(fpKey)
 		if processor != nil {
 			value := rvalue.FieldByName(field.Name)
 			if isValidValue(value) {
-				condition, arr := processor.Process(value.Elem())
+				condition, arr := processor.Process(d, value.Elem())
 				if condition != "" {
 					conditions = append(conditions, condition)
 					args = append(args, arr...)
@@ -12,6 +12,8 @@ package rdb
 
 import (
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	. "github.com/doytowin/goooqo/core"
@@ -121,3 +123,77 @@ INTERSECT SELECT role_id FROM a_role_and_perm WHERE perm_id IN (SELECT id FROM t
 	}
 
 }
+
+func TestBuildWhereClauseByCustomIdInPath(t *testing.T) {
+	buildEntityMetadata[GroupEntity](Dialect)
+	query := MemberQuery{Perm: &GroupPermQuery{Code: P("user:list"), GroupQuery: &GroupQuery{Name: P("dev")}}}
+	actual, args := BuildWhereClause(query)
+	expect := "SELECT group_no FROM t_group WHERE name = ?\nINTERSECT "
+	if !strings.Contains(actual, expect) {
+		t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+	}
+	if !reflect.DeepEqual(args, []any{"dev", "user:list"}) {
+		t.Errorf("BuildWhereClause() args = %v", args)
+	}
+}
+
+type regexpQuery struct {
+	MemoRx *string
+}
+
+func (q regexpQuery) BuildConditions(d DbDialect) ([]string, []any) {
+	return []string{d.BuildRegexp(d.Quote("memo"), "?")}, []any{*q.MemoRx}
+}
+
+func TestBuildWhereClauseByQueryBuilder(t *testing.T) {
+	actual, args := buildWhereClause(&PostgresDialect{}, regexpQuery{MemoRx: P("^f0")})
+	expect := ` WHERE "memo" ~ ?`
+	if actual != expect || !reflect.DeepEqual(args, []any{"^f0"}) {
+		t.Errorf("\nExpected: %s\nBut got : %s, %v", expect, actual, args)
+	}
+}
+
+type ConcurrentQuery struct {
+	PageQuery
+	IdIn       *[]int
+	ScoreLt    *int
+	EmailStart *string
+	EmailEndOr *[]string
+	Or         *ConcurrentQuery
+	Account    *string          `condition:"(username = ? OR email = ?)"`
+	ScoreLtAvg *ConcurrentQuery `subquery:"select:avg(score),from:ConcurrentEntity"`
+}
+
+func TestBuildWhereClauseConcurrently(t *testing.T) {
+	query := ConcurrentQuery{
+		IdIn: &[]int{1, 2}, ScoreLt: P(80), EmailStart: P("f0rb"),
+		EmailEndOr: &[]string{"qq.com", "gmail.com"},
+		Or:         &ConcurrentQuery{Account: P("f0rb"), ScoreLt: P(60)},
+		ScoreLtAvg: &ConcurrentQuery{ScoreLt: P(70)},
+	}
+	expect := " WHERE id IN (?, ?) AND score < ? AND email LIKE ? " +
+		"AND (email LIKE ? OR email LIKE ?) " +
+		"AND (score < ? OR (username = ? OR email = ?)) " +
+		"AND score < (SELECT avg(score) FROM t_concurrent_entity WHERE score < ?)"
+	expectArgs := []any{1, 2, 80, "f0rb%", "%qq.com", "%gmail.com", 60, "f0rb", "f0rb", 70}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				actual, args := BuildWhereClause(query)
+				if actual != expect {
+					t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+					return
+				}
+				if !reflect.DeepEqual(args, expectArgs) {
+					t.Errorf("BuildWhereClause() args = %v, expect %v", args, expectArgs)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
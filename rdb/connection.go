@@ -27,13 +27,23 @@ func Connect(filenames ...string) *sql.DB {
 		db, _ = sql.Open("sqlite3", "./test.db")
 		return db
 	}
+	var dialect DbDialect
 	driver := os.Getenv("driver")
 	if driver == "mysql" {
 		username := os.Getenv("mysql_username")
 		password := os.Getenv("mysql_password")
 		url := os.Getenv("mysql_url")
 		dataSourceName = username + ":" + password + "@" + url
-		Dialect = &MySQLDialect{}
+		dialect = &MySQLDialect{}
+	} else if driver == "postgres" || driver == "pgx" {
+		dataSourceName = os.Getenv("data_source")
+		dialect = &PostgresDialect{}
+	} else if driver == "sqlserver" || driver == "mssql" {
+		dataSourceName = os.Getenv("data_source")
+		dialect = &SQLServerDialect{}
+	} else if driver == "oracle" || driver == "godror" {
+		dataSourceName = os.Getenv("data_source")
+		dialect = &OracleDialect{}
 	} else {
 		dataSourceName = os.Getenv("data_source")
 	}
@@ -41,6 +51,9 @@ func Connect(filenames ...string) *sql.DB {
 	if err != nil {
 		panic(err)
 	}
+	if dialect != nil {
+		BindDialect(db, dialect)
+	}
 	return db
 
 }
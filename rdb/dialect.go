@@ -10,14 +10,128 @@
 
 package rdb
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
 
+// Dialect is the default dialect for the connections
+// and transaction managers without a bound dialect.
 var Dialect DbDialect = &BaseDialect{}
 
+// dialectMap holds the DbDialect by the bound target.
+var dialectMap sync.Map
+
+// BindDialect binds a dialect to a Connection or a TransactionManager,
+// so that the data access created on it uses the dialect instead of
+// the default Dialect.
+func BindDialect(target any, dialect DbDialect) {
+	dialectMap.Store(target, dialect)
+}
+
+// resolveDialect returns the dialect bound to the first bound target,
+// or the default Dialect when none of the targets is bound. A
+// RoutingConnection uses the dialect bound to its primary if unbound.
+func resolveDialect(targets ...any) DbDialect {
+	for _, target := range targets {
+		if dialect, ok := dialectMap.Load(target); ok {
+			return dialect.(DbDialect)
+		}
+		if rc, ok := target.(*RoutingConnection); ok {
+			if dialect, ok := dialectMap.Load(rc.primary); ok {
+				return dialect.(DbDialect)
+			}
+		}
+	}
+	return Dialect
+}
+
+type LockMode int
+
+const (
+	LockNone LockMode = iota
+	LockForShare
+	LockForUpdate
+)
+
+// LockQuery is implemented by the query objects
+// which need to lock the selected rows.
+type LockQuery interface {
+	GetLockMode() LockMode
+}
+
 type DbDialect interface {
 	BuildPageClause(sql string, offset int, size int) string
+
+	// RequireSortForPaging reports whether the page clause
+	// is only valid after an ORDER BY clause.
+	RequireSortForPaging() bool
+
+	// ResolvePlaceholders rewrites the `?` placeholders
+	// in sql to the bind variables of the database.
+	ResolvePlaceholders(sql string) string
+
+	// BuildReturningId returns the clause appended to an INSERT
+	// statement to read back the generated id, or an empty string
+	// when the id is retrieved by sql.Result.LastInsertId.
+	BuildReturningId(column string) string
+
+	// ReadsGeneratedId reports whether the id generated by
+	// the database can be read back after an INSERT statement.
+	ReadsGeneratedId() bool
+
+	// FirstInsertId returns the id of the first row inserted by
+	// a multi-row INSERT statement, given the single id read back
+	// by BuildReturningId or sql.Result.LastInsertId.
+	FirstInsertId(id int64, rows int) int64
+
+	// NullsFirst reports whether NULLs sort before
+	// the other values in the ascending order.
+	NullsFirst() bool
+
+	// Quote quotes a table name or a column name.
+	Quote(identifier string) string
+
+	// BuildInsertIgnore builds an INSERT statement for `rows` rows of
+	// `columns`, which skips the rows conflicting on the `keys` columns.
+	BuildInsertIgnore(table string, columns []string, keys []string, rows int) string
+
+	// BuildUpsert builds an INSERT statement for `rows` rows of `columns`,
+	// which updates the `updates` columns of the rows conflicting on
+	// the `keys` columns instead, and increases the `version` column
+	// when it is not empty. The conflicting rows are left unchanged
+	// unless they belong to the same tenant when `tenant` is not empty.
+	BuildUpsert(table string, columns []string, keys []string, updates []string, version string, tenant string, rows int) string
+
+	// BuildRowIn builds the condition matching the row of
+	// `columns` against `rows` rows of the placeholders.
+	BuildRowIn(columns []string, rows int) string
+
+	// BuildRegexp builds the condition matching column against a regex.
+	BuildRegexp(column string, placeholder string) string
+
+	// EscapeLike escapes the wildcards in value for a LIKE pattern.
+	EscapeLike(value string) string
+
+	// BuildLikeEscape returns the ESCAPE clause
+	// for the patterns escaped by EscapeLike.
+	BuildLikeEscape() string
+
+	// BuildLockClause locks the rows selected from table by sql.
+	BuildLockClause(sql string, table string, mode LockMode) string
+
+	// IsConstraintViolation reports whether err returned by
+	// the driver is caused by the violation of a constraint.
+	IsConstraintViolation(err error) bool
 }
 
+var likeEscapeRgx = regexp.MustCompile("[\\\\_%]")
+
+// BaseDialect emits identifiers as they are
+// and keeps the syntax compatible with SQLite.
 type BaseDialect struct {
 }
 
@@ -25,6 +139,458 @@ func (d *BaseDialect) BuildPageClause(sql string, offset int, size int) string {
 	return fmt.Sprintf("%s LIMIT %d OFFSET %d", sql, size, offset)
 }
 
+func (d *BaseDialect) RequireSortForPaging() bool {
+	return false
+}
+
+func (d *BaseDialect) ResolvePlaceholders(sql string) string {
+	return sql
+}
+
+func (d *BaseDialect) BuildReturningId(string) string {
+	return ""
+}
+
+func (d *BaseDialect) ReadsGeneratedId() bool {
+	return true
+}
+
+// FirstInsertId counts back from id which is the id
+// of the last row inserted, like SQLite reports.
+func (d *BaseDialect) FirstInsertId(id int64, rows int) int64 {
+	return id - int64(rows) + 1
+}
+
+func (d *BaseDialect) NullsFirst() bool {
+	return true
+}
+
+func (d *BaseDialect) Quote(identifier string) string {
+	return identifier
+}
+
+func (d *BaseDialect) BuildRowIn(columns []string, rows int) string {
+	row := "(?" + strings.Repeat(", ?", len(columns)-1) + ")"
+	return "(" + strings.Join(columns, ", ") + ") IN (" + strings.TrimPrefix(strings.Repeat(", "+row, rows), ", ") + ")"
+}
+
+func (d *BaseDialect) BuildInsertIgnore(table string, columns []string, _ []string, rows int) string {
+	return "INSERT OR IGNORE" + buildInsert(table, columns, rows)[len("INSERT"):]
+}
+
+func (d *BaseDialect) BuildUpsert(table string, columns []string, keys []string, updates []string, version string, tenant string, rows int) string {
+	return buildInsert(table, columns, rows) + " ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " +
+		joinUpdates(updates, func(col string) string { return "excluded." + col }, version, table) +
+		buildTenantGuard(" WHERE ", table, tenant, "excluded")
+}
+
+func (d *BaseDialect) BuildRegexp(column string, placeholder string) string {
+	return column + " REGEXP " + placeholder
+}
+
+func (d *BaseDialect) EscapeLike(value string) string {
+	return likeEscapeRgx.ReplaceAllString(value, "\\$0")
+}
+
+func (d *BaseDialect) BuildLikeEscape() string {
+	return " ESCAPE '\\'"
+}
+
+func (d *BaseDialect) BuildLockClause(sql string, _ string, _ LockMode) string {
+	return sql
+}
+
+func (d *BaseDialect) IsConstraintViolation(err error) bool {
+	return strings.Contains(err.Error(), "constraint failed")
+}
+
+// SQLiteDialect quotes identifiers by double quotes
+// and leaves the locking to the database file.
+type SQLiteDialect struct {
+	BaseDialect
+}
+
+func (d *SQLiteDialect) Quote(identifier string) string {
+	return quote(identifier, `"`, `"`)
+}
+
 type MySQLDialect struct {
 	BaseDialect
 }
+
+// FirstInsertId returns id as it is since LAST_INSERT_ID
+// reports the id of the first row inserted by a statement.
+func (d *MySQLDialect) FirstInsertId(id int64, _ int) int64 {
+	return id
+}
+
+func (d *MySQLDialect) Quote(identifier string) string {
+	return quote(identifier, "`", "`")
+}
+
+func (d *MySQLDialect) BuildInsertIgnore(table string, columns []string, _ []string, rows int) string {
+	return "INSERT IGNORE" + buildInsert(table, columns, rows)[len("INSERT"):]
+}
+
+// BuildUpsert guards each assignment by the tenant
+// since ON DUPLICATE KEY UPDATE accepts no WHERE clause.
+func (d *MySQLDialect) BuildUpsert(table string, columns []string, _ []string, updates []string, version string, tenant string, rows int) string {
+	guard := func(value string, col string) string {
+		if tenant == "" {
+			return value
+		}
+		return "IF(" + tenant + " = VALUES(" + tenant + "), " + value + ", " + col + ")"
+	}
+	set := joinSet(updates, func(col string) string { return guard("VALUES("+col+")", col) })
+	if version != "" {
+		set = joinNonEmpty(set, version+" = "+guard(version+" + 1", version))
+	}
+	return buildInsert(table, columns, rows) + " ON DUPLICATE KEY UPDATE " + set
+}
+
+// BuildLikeEscape returns an empty string
+// since backslash is the default escape character.
+func (d *MySQLDialect) BuildLikeEscape() string {
+	return ""
+}
+
+func (d *MySQLDialect) BuildLockClause(sql string, _ string, mode LockMode) string {
+	switch mode {
+	case LockForShare:
+		return sql + " LOCK IN SHARE MODE"
+	case LockForUpdate:
+		return sql + " FOR UPDATE"
+	}
+	return sql
+}
+
+var mysqlConstraintRgx = regexp.MustCompile(`^Error (1048|1062|1216|1217|1451|1452|3819)\b`)
+
+func (d *MySQLDialect) IsConstraintViolation(err error) bool {
+	return mysqlConstraintRgx.MatchString(err.Error())
+}
+
+type PostgresDialect struct {
+	BaseDialect
+}
+
+func (d *PostgresDialect) ResolvePlaceholders(sql string) string {
+	return numberPlaceholders(sql, "$")
+}
+
+func (d *PostgresDialect) BuildReturningId(column string) string {
+	return " RETURNING " + column
+}
+
+// NullsFirst returns false since PostgreSQL sorts
+// NULLs as larger than the other values.
+func (d *PostgresDialect) NullsFirst() bool {
+	return false
+}
+
+func (d *PostgresDialect) Quote(identifier string) string {
+	return quote(identifier, `"`, `"`)
+}
+
+func (d *PostgresDialect) BuildInsertIgnore(table string, columns []string, keys []string, rows int) string {
+	conflict := " ON CONFLICT"
+	if len(keys) > 0 {
+		conflict += " (" + strings.Join(keys, ", ") + ")"
+	}
+	return buildInsert(table, columns, rows) + conflict + " DO NOTHING"
+}
+
+func (d *PostgresDialect) BuildUpsert(table string, columns []string, keys []string, updates []string, version string, tenant string, rows int) string {
+	return buildInsert(table, columns, rows) + " ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " +
+		joinUpdates(updates, func(col string) string { return "EXCLUDED." + col }, version, table) +
+		buildTenantGuard(" WHERE ", table, tenant, "EXCLUDED")
+}
+
+func (d *PostgresDialect) BuildRegexp(column string, placeholder string) string {
+	return column + " ~ " + placeholder
+}
+
+// BuildLikeEscape returns an empty string
+// since backslash is the default escape character.
+func (d *PostgresDialect) BuildLikeEscape() string {
+	return ""
+}
+
+func (d *PostgresDialect) BuildLockClause(sql string, _ string, mode LockMode) string {
+	switch mode {
+	case LockForShare:
+		return sql + " FOR SHARE"
+	case LockForUpdate:
+		return sql + " FOR UPDATE"
+	}
+	return sql
+}
+
+// IsConstraintViolation matches the message of lib/pq and
+// the SQLSTATE of the integrity constraint violation of pgx.
+func (d *PostgresDialect) IsConstraintViolation(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "violates") || strings.Contains(msg, "(SQLSTATE 23")
+}
+
+type SQLServerDialect struct {
+	BaseDialect
+}
+
+func (d *SQLServerDialect) BuildPageClause(sql string, offset int, size int) string {
+	return buildOffsetFetch(sql, offset, size)
+}
+
+func (d *SQLServerDialect) RequireSortForPaging() bool {
+	return true
+}
+
+func (d *SQLServerDialect) ResolvePlaceholders(sql string) string {
+	return numberPlaceholders(sql, "@p")
+}
+
+func (d *SQLServerDialect) BuildReturningId(string) string {
+	return "; SELECT CAST(SCOPE_IDENTITY() AS BIGINT)"
+}
+
+func (d *SQLServerDialect) Quote(identifier string) string {
+	return quote(identifier, "[", "]")
+}
+
+func (d *SQLServerDialect) BuildInsertIgnore(table string, columns []string, keys []string, rows int) string {
+	return buildMerge(table, columns, keys, rows) + buildMergeInsert(columns) + ";"
+}
+
+func (d *SQLServerDialect) BuildUpsert(table string, columns []string, keys []string, updates []string, version string, tenant string, rows int) string {
+	return buildMerge(table, columns, keys, rows) +
+		" WHEN MATCHED" + buildTenantGuard(" AND ", "t", tenant, "s") + " THEN UPDATE SET " +
+		joinUpdates(updates, func(col string) string { return "s." + col }, version, "t") +
+		buildMergeInsert(columns) + ";"
+}
+
+// BuildRowIn expands the rows to the conditions joined by OR
+// since SQL Server supports no row value constructor in IN.
+func (d *SQLServerDialect) BuildRowIn(columns []string, rows int) string {
+	row := "(" + strings.Join(columns, " = ? AND ") + " = ?)"
+	return strings.TrimPrefix(strings.Repeat(" OR "+row, rows), " OR ")
+}
+
+// BuildRegexp uses REGEXP_LIKE, which is
+// only available since SQL Server 2025.
+func (d *SQLServerDialect) BuildRegexp(column string, placeholder string) string {
+	return "REGEXP_LIKE(" + column + ", " + placeholder + ")"
+}
+
+var sqlServerLikeEscapeRgx = regexp.MustCompile("[\\\\_%\\[]")
+
+func (d *SQLServerDialect) EscapeLike(value string) string {
+	return sqlServerLikeEscapeRgx.ReplaceAllString(value, "\\$0")
+}
+
+func (d *SQLServerDialect) BuildLockClause(sql string, table string, mode LockMode) string {
+	hint := ""
+	switch mode {
+	case LockForShare:
+		hint = " WITH (HOLDLOCK, ROWLOCK)"
+	case LockForUpdate:
+		hint = " WITH (UPDLOCK, ROWLOCK)"
+	default:
+		return sql
+	}
+	from := " FROM " + table
+	return strings.Replace(sql, from, from+hint, 1)
+}
+
+func (d *SQLServerDialect) IsConstraintViolation(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "Violation of") || strings.Contains(msg, "conflicted with the") ||
+		strings.Contains(msg, "Cannot insert the value NULL")
+}
+
+// OracleDialect targets Oracle 12c+, which supports OFFSET ... FETCH.
+// The generated id is not read back since Oracle needs an output
+// bind variable for RETURNING ... INTO and supports no LastInsertId.
+type OracleDialect struct {
+	BaseDialect
+}
+
+func (d *OracleDialect) BuildPageClause(sql string, offset int, size int) string {
+	return buildOffsetFetch(sql, offset, size)
+}
+
+func (d *OracleDialect) RequireSortForPaging() bool {
+	return true
+}
+
+func (d *OracleDialect) ReadsGeneratedId() bool {
+	return false
+}
+
+func (d *OracleDialect) ResolvePlaceholders(sql string) string {
+	return numberPlaceholders(sql, ":")
+}
+
+// NullsFirst returns false since Oracle sorts
+// NULLs as larger than the other values.
+func (d *OracleDialect) NullsFirst() bool {
+	return false
+}
+
+func (d *OracleDialect) Quote(identifier string) string {
+	return quote(identifier, `"`, `"`)
+}
+
+func (d *OracleDialect) BuildInsertIgnore(table string, columns []string, keys []string, rows int) string {
+	return buildDualMerge(table, columns, keys, rows) + buildMergeInsert(columns)
+}
+
+func (d *OracleDialect) BuildUpsert(table string, columns []string, keys []string, updates []string, version string, tenant string, rows int) string {
+	return buildDualMerge(table, columns, keys, rows) +
+		" WHEN MATCHED THEN UPDATE SET " + joinUpdates(updates, func(col string) string { return "s." + col }, version, "t") +
+		buildTenantGuard(" WHERE ", "t", tenant, "s") + buildMergeInsert(columns)
+}
+
+func (d *OracleDialect) BuildRegexp(column string, placeholder string) string {
+	return "REGEXP_LIKE(" + column + ", " + placeholder + ")"
+}
+
+// BuildLockClause treats LockForShare as no lock
+// since Oracle only supports FOR UPDATE.
+func (d *OracleDialect) BuildLockClause(sql string, _ string, mode LockMode) string {
+	if mode == LockForUpdate {
+		return sql + " FOR UPDATE"
+	}
+	return sql
+}
+
+var oracleConstraintRgx = regexp.MustCompile(`ORA-(00001|01400|02290|02291|02292):`)
+
+func (d *OracleDialect) IsConstraintViolation(err error) bool {
+	return oracleConstraintRgx.MatchString(err.Error())
+}
+
+func quote(identifier string, open string, close string) string {
+	if identifier == "" || strings.HasPrefix(identifier, open) {
+		return identifier
+	}
+	return open + strings.ReplaceAll(identifier, close, close+close) + close
+}
+
+// quoteColumns quotes each of columns by d.
+func quoteColumns(d DbDialect, columns []string) []string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = d.Quote(column)
+	}
+	return quoted
+}
+
+func buildValues(columns int, rows int) string {
+	row := "(?" + strings.Repeat(", ?", columns-1) + ")"
+	return row + strings.Repeat(", "+row, rows-1)
+}
+
+func buildInsert(table string, columns []string, rows int) string {
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES " + buildValues(len(columns), rows)
+}
+
+func joinSet(columns []string, value func(col string) string) string {
+	set := make([]string, len(columns))
+	for i, col := range columns {
+		set[i] = col + " = " + value(col)
+	}
+	return strings.Join(set, ", ")
+}
+
+// joinUpdates joins the assignments of the updates columns
+// followed by the increment of the version column of target.
+func joinUpdates(updates []string, value func(col string) string, version string, target string) string {
+	set := joinSet(updates, value)
+	if version == "" {
+		return set
+	}
+	return joinNonEmpty(set, version+" = "+target+"."+version+" + 1")
+}
+
+func joinNonEmpty(set string, assignment string) string {
+	if set == "" {
+		return assignment
+	}
+	return set + ", " + assignment
+}
+
+// buildTenantGuard builds the condition to update the conflicting
+// row of target only when it belongs to the tenant of the source row.
+func buildTenantGuard(prefix string, target string, tenant string, source string) string {
+	if tenant == "" {
+		return ""
+	}
+	return prefix + target + "." + tenant + " = " + source + "." + tenant
+}
+
+func buildMerge(table string, columns []string, keys []string, rows int) string {
+	return "MERGE INTO " + table + " AS t USING (VALUES " + buildValues(len(columns), rows) + ")" +
+		" AS s (" + strings.Join(columns, ", ") + ") ON " + joinOn(keys)
+}
+
+func buildOffsetFetch(sql string, offset int, size int) string {
+	return fmt.Sprintf("%s OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", sql, offset, size)
+}
+
+// buildDualMerge builds the MERGE statement of Oracle,
+// which selects the rows from dual instead of VALUES.
+func buildDualMerge(table string, columns []string, keys []string, rows int) string {
+	selects := make([]string, len(columns))
+	for i, col := range columns {
+		selects[i] = "? " + col
+	}
+	row := "SELECT " + strings.Join(selects, ", ") + " FROM dual"
+	return "MERGE INTO " + table + " t USING (" + row + strings.Repeat(" UNION ALL "+row, rows-1) + ") s" +
+		" ON (" + joinOn(keys) + ")"
+}
+
+func joinOn(keys []string) string {
+	on := make([]string, len(keys))
+	for i, key := range keys {
+		on[i] = "t." + key + " = s." + key
+	}
+	return strings.Join(on, " AND ")
+}
+
+func buildMergeInsert(columns []string) string {
+	values := make([]string, len(columns))
+	for i, col := range columns {
+		values[i] = "s." + col
+	}
+	return " WHEN NOT MATCHED THEN INSERT (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(values, ", ") + ")"
+}
+
+// numberPlaceholders replaces each `?` outside quoted
+// literals and identifiers with prefix followed by
+// its 1-based position, e.g. `a = ? AND b = ?` -> `a = $1 AND b = $2`.
+func numberPlaceholders(sql string, prefix string) string {
+	if !strings.Contains(sql, "?") {
+		return sql
+	}
+	var sb strings.Builder
+	sb.Grow(len(sql) + 8)
+	var quoted rune
+	n := 0
+	for _, c := range sql {
+		switch {
+		case quoted != 0:
+			if c == quoted {
+				quoted = 0
+			}
+		case c == '\'' || c == '"':
+			quoted = c
+		case c == '?':
+			n++
+			sb.WriteString(prefix)
+			sb.WriteString(strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
@@ -0,0 +1,329 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package rdb
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/doytowin/goooqo/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresDialect(t *testing.T) {
+	dialect := &PostgresDialect{}
+
+	tests := []struct {
+		name   string
+		sql    string
+		expect string
+	}{
+		{
+			"Number placeholders in order",
+			"SELECT id, score, memo FROM t_user WHERE id > ? AND score < ?",
+			"SELECT id, score, memo FROM t_user WHERE id > $1 AND score < $2",
+		},
+		{
+			"Keep statement without placeholders",
+			"SELECT count(0) FROM t_user",
+			"SELECT count(0) FROM t_user",
+		},
+		{
+			"Skip question marks in literals and quoted identifiers",
+			`SELECT "a?b" FROM t_user WHERE memo LIKE ? ESCAPE '\' AND memo <> '?' AND id IN (?, ?)`,
+			`SELECT "a?b" FROM t_user WHERE memo LIKE $1 ESCAPE '\' AND memo <> '?' AND id IN ($2, $3)`,
+		},
+		{
+			"Number placeholders across subqueries",
+			"UPDATE t_user SET memo = ? WHERE score < (SELECT avg(score) FROM t_user WHERE memo LIKE ?)",
+			"UPDATE t_user SET memo = $1 WHERE score < (SELECT avg(score) FROM t_user WHERE memo LIKE $2)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := dialect.ResolvePlaceholders(tt.sql); actual != tt.expect {
+				t.Errorf("\nExpected: %s\nBut got : %s", tt.expect, actual)
+			}
+		})
+	}
+
+	t.Run("Build RETURNING clause", func(t *testing.T) {
+		actual := dialect.BuildReturningId("id")
+		expect := " RETURNING id"
+		if actual != expect {
+			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+		}
+	})
+
+	t.Run("BaseDialect keeps placeholders", func(t *testing.T) {
+		sql := "SELECT id FROM t_user WHERE id = ?"
+		if actual := (&BaseDialect{}).ResolvePlaceholders(sql); actual != sql {
+			t.Errorf("\nExpected: %s\nBut got : %s", sql, actual)
+		}
+	})
+}
+
+func TestFirstInsertId(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect DbDialect
+		expect  int64
+	}{
+		{"SQLite reports the last id", &SQLiteDialect{}, 8},
+		{"MySQL reports the first id", &MySQLDialect{}, 10},
+		{"SQL Server reports the last id", &SQLServerDialect{}, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.dialect.FirstInsertId(10, 3); actual != tt.expect {
+				t.Errorf("\nExpected: %d\nBut got : %d", tt.expect, actual)
+			}
+		})
+	}
+}
+
+func TestDialects(t *testing.T) {
+	columns := []string{"id", "score", "memo"}
+	keys := []string{"id"}
+	updates := []string{"score", "memo"}
+
+	tests := []struct {
+		name    string
+		dialect DbDialect
+		quote   string
+		ignore  string
+		upsert  string
+		regexp  string
+		escape  string
+		lock    string
+	}{
+		{
+			"SQLite",
+			&SQLiteDialect{},
+			`"t_user"`,
+			"INSERT OR IGNORE INTO t_user (id, score, memo) VALUES (?, ?, ?), (?, ?, ?)",
+			"INSERT INTO t_user (id, score, memo) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (id) DO UPDATE SET score = excluded.score, memo = excluded.memo",
+			"memo REGEXP ?",
+			"memo LIKE ? ESCAPE '\\'",
+			"SELECT id FROM t_user WHERE id = ?",
+		},
+		{
+			"MySQL",
+			&MySQLDialect{},
+			"`t_user`",
+			"INSERT IGNORE INTO t_user (id, score, memo) VALUES (?, ?, ?), (?, ?, ?)",
+			"INSERT INTO t_user (id, score, memo) VALUES (?, ?, ?), (?, ?, ?) ON DUPLICATE KEY UPDATE score = VALUES(score), memo = VALUES(memo)",
+			"memo REGEXP ?",
+			"memo LIKE ?",
+			"SELECT id FROM t_user WHERE id = ? FOR UPDATE",
+		},
+		{
+			"PostgreSQL",
+			&PostgresDialect{},
+			`"t_user"`,
+			"INSERT INTO t_user (id, score, memo) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (id) DO NOTHING",
+			"INSERT INTO t_user (id, score, memo) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (id) DO UPDATE SET score = EXCLUDED.score, memo = EXCLUDED.memo",
+			"memo ~ ?",
+			"memo LIKE ?",
+			"SELECT id FROM t_user WHERE id = ? FOR UPDATE",
+		},
+		{
+			"SQL Server",
+			&SQLServerDialect{},
+			"[t_user]",
+			"MERGE INTO t_user AS t USING (VALUES (?, ?, ?), (?, ?, ?)) AS s (id, score, memo) ON t.id = s.id" +
+				" WHEN NOT MATCHED THEN INSERT (id, score, memo) VALUES (s.id, s.score, s.memo);",
+			"MERGE INTO t_user AS t USING (VALUES (?, ?, ?), (?, ?, ?)) AS s (id, score, memo) ON t.id = s.id" +
+				" WHEN MATCHED THEN UPDATE SET score = s.score, memo = s.memo" +
+				" WHEN NOT MATCHED THEN INSERT (id, score, memo) VALUES (s.id, s.score, s.memo);",
+			"REGEXP_LIKE(memo, ?)",
+			"memo LIKE ? ESCAPE '\\'",
+			"SELECT id FROM t_user WITH (UPDLOCK, ROWLOCK) WHERE id = ?",
+		},
+		{
+			"Oracle",
+			&OracleDialect{},
+			`"t_user"`,
+			"MERGE INTO t_user t USING (SELECT ? id, ? score, ? memo FROM dual UNION ALL SELECT ? id, ? score, ? memo FROM dual) s ON (t.id = s.id)" +
+				" WHEN NOT MATCHED THEN INSERT (id, score, memo) VALUES (s.id, s.score, s.memo)",
+			"MERGE INTO t_user t USING (SELECT ? id, ? score, ? memo FROM dual UNION ALL SELECT ? id, ? score, ? memo FROM dual) s ON (t.id = s.id)" +
+				" WHEN MATCHED THEN UPDATE SET score = s.score, memo = s.memo" +
+				" WHEN NOT MATCHED THEN INSERT (id, score, memo) VALUES (s.id, s.score, s.memo)",
+			"REGEXP_LIKE(memo, ?)",
+			"memo LIKE ? ESCAPE '\\'",
+			"SELECT id FROM t_user WHERE id = ? FOR UPDATE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.quote, tt.dialect.Quote("t_user"))
+			assert.Equal(t, tt.ignore, tt.dialect.BuildInsertIgnore("t_user", columns, keys, 2))
+			assert.Equal(t, tt.upsert, tt.dialect.BuildUpsert("t_user", columns, keys, updates, "", "", 2))
+			assert.Equal(t, tt.regexp, tt.dialect.BuildRegexp("memo", "?"))
+			assert.Equal(t, tt.escape, "memo LIKE ?"+tt.dialect.BuildLikeEscape())
+			assert.Equal(t, tt.lock, tt.dialect.BuildLockClause("SELECT id FROM t_user WHERE id = ?", "t_user", LockForUpdate))
+		})
+	}
+
+	t.Run("Guard the upsert by the tenant", func(t *testing.T) {
+		tests := []struct {
+			dialect DbDialect
+			expect  string
+		}{
+			{&SQLiteDialect{}, " ON CONFLICT (id) DO UPDATE SET score = excluded.score WHERE t_user.tenant_id = excluded.tenant_id"},
+			{&MySQLDialect{}, " ON DUPLICATE KEY UPDATE score = IF(tenant_id = VALUES(tenant_id), VALUES(score), score)"},
+			{&PostgresDialect{}, " ON CONFLICT (id) DO UPDATE SET score = EXCLUDED.score WHERE t_user.tenant_id = EXCLUDED.tenant_id"},
+			{&SQLServerDialect{}, " WHEN MATCHED AND t.tenant_id = s.tenant_id THEN UPDATE SET score = s.score"},
+			{&OracleDialect{}, " WHEN MATCHED THEN UPDATE SET score = s.score WHERE t.tenant_id = s.tenant_id"},
+		}
+		columns := []string{"id", "score", "tenant_id"}
+		for _, tt := range tests {
+			actual := tt.dialect.BuildUpsert("t_user", columns, keys, []string{"score"}, "", "tenant_id", 1)
+			assert.Contains(t, actual, tt.expect)
+		}
+	})
+
+	t.Run("Increase the version on upsert", func(t *testing.T) {
+		tests := []struct {
+			dialect DbDialect
+			tenant  string
+			expect  string
+		}{
+			{&SQLiteDialect{}, "", " DO UPDATE SET score = excluded.score, version = t_user.version + 1"},
+			{&MySQLDialect{}, "", " UPDATE score = VALUES(score), version = version + 1"},
+			{&MySQLDialect{}, "tenant_id", ", version = IF(tenant_id = VALUES(tenant_id), version + 1, version)"},
+			{&PostgresDialect{}, "", " DO UPDATE SET score = EXCLUDED.score, version = t_user.version + 1"},
+			{&SQLServerDialect{}, "", " THEN UPDATE SET score = s.score, version = t.version + 1"},
+			{&OracleDialect{}, "", " THEN UPDATE SET score = s.score, version = t.version + 1"},
+		}
+		columns := []string{"id", "score", "version"}
+		for _, tt := range tests {
+			actual := tt.dialect.BuildUpsert("t_user", columns, keys, []string{"score"}, "version", tt.tenant, 1)
+			assert.Contains(t, actual, tt.expect)
+		}
+	})
+
+	t.Run("SQL Server escapes bracket wildcards", func(t *testing.T) {
+		assert.Equal(t, `\[a]\_\%`, (&SQLServerDialect{}).EscapeLike("[a]_%"))
+	})
+
+	t.Run("Builders consult the dialect", func(t *testing.T) {
+		dialect := &PostgresDialect{}
+
+		where, args := buildWhereClause(dialect, TestQuery{EmailRx: P("@qq\\.com$"), EmailStart: P("f0_rb")})
+		assert.Equal(t, ` WHERE "email" LIKE ? AND "email" ~ ?`, where)
+		assert.Equal(t, []any{"f0\\_rb%", "@qq\\.com$"}, args)
+
+		em := buildEntityMetadata[TestEntity](dialect)
+		query := &LockedQuery{Username: P("f0rb")}
+		actual, _, _ := em.buildSelect(query)
+		assert.Equal(t, `SELECT "id", "username", "email", "mobile", "create_time" FROM "t_user" WHERE "username" = ? FOR UPDATE`, actual)
+	})
+
+	t.Run("Quote the columns of the conditions and the sort", func(t *testing.T) {
+		em := buildEntityMetadata[TestEntity](&MySQLDialect{})
+		columns := "SELECT `id`, `username`, `email`, `mobile`, `create_time` FROM `t_user`"
+
+		actual, _, _ := em.buildSelect(&TestQuery{PageQuery: PageQuery{Size: 5, Sort: "username,desc"}, Username: P("f0rb")})
+		assert.Equal(t, columns+" WHERE `username` = ? ORDER BY `username` DESC LIMIT 5 OFFSET 0", actual)
+
+		actual, _, _ = em.buildSelectById(1)
+		assert.Equal(t, columns+" WHERE `id` = ?", actual)
+
+		cursor, _ := EncodeCursor([]any{"f0rb", 3})
+		actual, _, _ = em.buildCursorSelect(&TestQuery{PageQuery: PageQuery{Size: 5, Sort: "username"}}, cursor)
+		assert.Equal(t, columns+" WHERE (`username` > ? OR (`username` = ? AND `id` > ?)) ORDER BY `username`, `id` LIMIT 5 OFFSET 0", actual)
+	})
+}
+
+func TestOffsetFetchPaging(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect DbDialect
+		query   Query
+		expect  string
+	}{
+		{
+			"SQL Server sorts by id when paging without sort",
+			&SQLServerDialect{},
+			&TestQuery{PageQuery: PageQuery{Page: 3, Size: 10}},
+			"SELECT [id], [username], [email], [mobile], [create_time] FROM [t_user] ORDER BY [id] OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			"Oracle keeps the sort of the query",
+			&OracleDialect{},
+			&TestQuery{PageQuery: PageQuery{Size: 5, Sort: "username,desc"}},
+			`SELECT "id", "username", "email", "mobile", "create_time" FROM "t_user" ORDER BY "username" DESC OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY`,
+		},
+		{
+			"Oracle does not sort without paging",
+			&OracleDialect{},
+			&TestQuery{Username: P("f0rb")},
+			`SELECT "id", "username", "email", "mobile", "create_time" FROM "t_user" WHERE "username" = ?`,
+		},
+		{
+			"LIMIT OFFSET needs no default sort",
+			&BaseDialect{},
+			&TestQuery{PageQuery: PageQuery{Page: 2, Size: 10}},
+			"SELECT id, username, email, mobile, create_time FROM t_user LIMIT 10 OFFSET 10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			em := buildEntityMetadata[TestEntity](tt.dialect)
+			actual, _, _ := em.buildSelect(tt.query)
+			assert.Equal(t, tt.expect, actual)
+		})
+	}
+
+	t.Run("Oracle does not read back the generated id", func(t *testing.T) {
+		assert.False(t, (&OracleDialect{}).ReadsGeneratedId())
+		assert.True(t, (&SQLiteDialect{}).ReadsGeneratedId())
+	})
+
+	t.Run("Oracle numbers placeholders with colon", func(t *testing.T) {
+		actual := (&OracleDialect{}).ResolvePlaceholders("SELECT id FROM t_user WHERE id = ? AND memo = '?' AND score > ?")
+		assert.Equal(t, "SELECT id FROM t_user WHERE id = :1 AND memo = '?' AND score > :2", actual)
+	})
+}
+
+func TestIsConstraintViolation(t *testing.T) {
+	tests := []struct {
+		dialect DbDialect
+		msg     string
+		expect  bool
+	}{
+		{&SQLiteDialect{}, "UNIQUE constraint failed: a_user_and_role.user_id, a_user_and_role.role_id", true},
+		{&SQLiteDialect{}, "no such table: t_user", false},
+		{&MySQLDialect{}, "Error 1062 (23000): Duplicate entry '1' for key 'PRIMARY'", true},
+		{&MySQLDialect{}, "Error 1146 (42S02): Table 'test.t_user' doesn't exist", false},
+		{&PostgresDialect{}, `pq: duplicate key value violates unique constraint "user_pk"`, true},
+		{&PostgresDialect{}, "ERROR: null value in column (SQLSTATE 23502)", true},
+		{&PostgresDialect{}, `ERROR: relation "t_user" does not exist (SQLSTATE 42P01)`, false},
+		{&SQLServerDialect{}, "mssql: Violation of PRIMARY KEY constraint 'user_pk'.", true},
+		{&OracleDialect{}, "ORA-00001: unique constraint (TEST.USER_PK) violated", true},
+		{&OracleDialect{}, "ORA-00942: table or view does not exist", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.dialect.IsConstraintViolation(errors.New(tt.msg)))
+		})
+	}
+}
+
+type LockedQuery struct {
+	PageQuery
+	Username *string
+}
+
+func (q *LockedQuery) GetLockMode() LockMode {
+	return LockForUpdate
+}
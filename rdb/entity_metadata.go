@@ -11,77 +11,349 @@
 package rdb
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	. "github.com/doytowin/goooqo/core"
 )
 
-var whereId = " WHERE id = ?"
-var emMap = make(map[string]*metadata)
+// emMap holds the *metadata by the name of the entity.
+var emMap sync.Map
 
 type metadata struct {
 	TableName string
+	idColumn  string
 }
 
 type EntityMetadata[E Entity] struct {
 	metadata
+	dialect         DbDialect
 	columnMetas     []FieldMetadata
 	relationMetas   []FieldMetadata
+	idColumns       []string
+	idFields        []FieldMetadata
+	whereId         string
 	ColStr          string
 	fieldsWithoutId []string
+	insertFields    []string
+	assignedId      bool
+	idGenerator     IdGenerator
 	createStr       string
 	placeholders    string
 	updateStr       string
+	upsertColumns   []string
+	upsertKeys      []string
+	upsertUpdates   []string
+	upsertById      bool
+	softDelete      string
+	withDeleted     bool
+	version         string
+	versionField    string
+	updateFields    []string
+	audit           AuditFields
+	tenant          string
+	tenantField     string
+	tenantId        any
 	Type            reflect.Type
 }
 
 func RegisterEntity(entityName string, tableName string) {
-	emMap[entityName] = &metadata{TableName: tableName}
+	emMap.Store(entityName, &metadata{TableName: tableName})
+}
+
+// lookupDomain returns the metadata of the registered entity named
+// by domain, e.g. UserEntity or User for `user`, which is absent
+// until a DataAccess of the entity is created.
+func lookupDomain(domain string) (*metadata, bool) {
+	for _, name := range []string{Capitalize(domain) + "Entity", Capitalize(domain)} {
+		if md, ok := emMap.Load(name); ok {
+			return md.(*metadata), true
+		}
+	}
+	return nil, false
+}
+
+// idColumnOf returns the id column of the entity named by
+// domain, or `id` when the entity is not registered yet.
+func idColumnOf(domain string) string {
+	if md, ok := lookupDomain(domain); ok && md.idColumn != "" {
+		return md.idColumn
+	}
+	return "id"
+}
+
+// idColumnsOf returns the columns of the id fields,
+// or `id` when none of the fields is the id.
+func idColumnsOf(fieldMetas []FieldMetadata) []string {
+	idColumns := make([]string, 0, 1)
+	for _, md := range fieldMetas {
+		if md.IsId && md.EntityPath == nil {
+			idColumns = append(idColumns, md.ColumnName)
+		}
+	}
+	if len(idColumns) == 0 {
+		idColumns = []string{"id"}
+	}
+	return idColumns
 }
 
 func (em *EntityMetadata[E]) buildArgs(entity E) []any {
-	args := make([]any, len(em.fieldsWithoutId))
+	args := em.readArgs(entity, em.insertFields)
+	for i, col := range em.insertFields {
+		if col == em.versionField && args[i] == nil {
+			args[i] = 0
+		} else if col == em.tenantField {
+			args[i] = em.tenantId
+		}
+	}
+	return args
+}
+
+func (em *EntityMetadata[E]) readArgs(entity any, fields []string) []any {
+	args := make([]any, len(fields))
 	rv := reflect.ValueOf(entity)
-	for i, col := range em.fieldsWithoutId {
+	for i, col := range fields {
 		value := rv.FieldByName(col)
 		args[i] = ReadValue(value)
 	}
 	return args
 }
 
-func (em *EntityMetadata[E]) buildSelect(query Query) (string, []any) {
-	whereClause, args := BuildWhereClause(query)
-	s := "SELECT " + em.ColStr + " FROM " + em.TableName + whereClause
-	s += BuildSortClause(query.GetSort())
-	if query.NeedPaging() {
-		s = Dialect.BuildPageClause(s, query.CalcOffset(), query.GetPageSize())
+// isComposite reports whether the entity has a composite key.
+func (em *EntityMetadata[E]) isComposite() bool {
+	return len(em.idFields) > 1
+}
+
+// buildIdArgs resolves id to the args of the WHERE clause by the
+// id, which are the values of the id fields for a composite key.
+func (em *EntityMetadata[E]) buildIdArgs(id any) ([]any, error) {
+	if !em.isComposite() {
+		return []any{id}, nil
+	}
+	return ReadIdValues(id, em.idFields)
+}
+
+// readIdArgs reads the args of the WHERE clause by the id from entity.
+func (em *EntityMetadata[E]) readIdArgs(entity Entity) []any {
+	if !em.isComposite() {
+		return []any{entity.GetId()}
+	}
+	fields := make([]string, len(em.idFields))
+	for i, md := range em.idFields {
+		fields[i] = md.Field.Name
+	}
+	return em.readArgs(entity, fields)
+}
+
+// readVersion reads the value of the version field of entity,
+// which is nil when the entity has no version to check.
+func (em *EntityMetadata[E]) readVersion(entity any) any {
+	if em.versionField == "" {
+		return nil
+	}
+	value := reflect.ValueOf(entity).FieldByName(em.versionField)
+	if !value.IsValid() {
+		return nil
+	}
+	return ReadValue(value)
+}
+
+// appendVersion appends the condition of the version
+// to the UPDATE statement when entity has the version.
+func (em *EntityMetadata[E]) appendVersion(entity any, sqlStr string, args []any) (string, []any) {
+	if version := em.readVersion(entity); version != nil {
+		return sqlStr + " AND " + em.version + " = ?", append(args, version)
+	}
+	return sqlStr, args
+}
+
+// scope returns a copy of em bound to the tenant in ctx for the
+// entity with a `tenant` field and reading the soft-deleted records
+// when ctx is WithDeleted, or em itself for the other entities.
+func (em *EntityMetadata[E]) scope(ctx context.Context) (*EntityMetadata[E], error) {
+	tenantId, err := RequireTenantId(ctx, em.tenantField)
+	withDeleted := em.softDelete != "" && IsWithDeleted(ctx)
+	if err != nil || tenantId == nil && !withDeleted {
+		return em, err
 	}
-	return s, args
+	scoped := *em
+	scoped.tenantId, scoped.withDeleted = tenantId, withDeleted
+	return &scoped, nil
 }
 
-func (em *EntityMetadata[E]) buildSelectById() string {
-	return "SELECT " + em.ColStr + " FROM " + em.TableName + whereId
+// filterTenant appends the condition of the tenant
+// to the WHERE clause for the entity with a `tenant` field.
+func (em *EntityMetadata[E]) filterTenant(whereClause string, args []any) (string, []any) {
+	if em.tenant == "" {
+		return whereClause, args
+	}
+	whereClause = Ternary(whereClause == "", " WHERE ", whereClause+" AND ") + em.tenant + " = ?"
+	return whereClause, append(args, em.tenantId)
+}
+
+// filterScope filters out the soft-deleted records and
+// the records of the other tenants from the WHERE clause.
+func (em *EntityMetadata[E]) filterScope(whereClause string, args []any) (string, []any) {
+	whereClause, args = em.filterDeleted(whereClause, args)
+	return em.filterTenant(whereClause, args)
+}
+
+func (em *EntityMetadata[E]) buildSelect(query Query) (string, []any, error) {
+	var s string
+	var args []any
+	if after, ok := ReadAfter(query); ok {
+		var err error
+		if s, args, err = em.buildCursorSelect(query, after); err != nil {
+			return "", nil, err
+		}
+	} else {
+		if err := ValidateSort(query, em.columnNames()); err != nil {
+			return "", nil, err
+		}
+		var whereClause string
+		whereClause, args = buildWhereClause(em.dialect, query)
+		whereClause, args = em.filterScope(whereClause, args)
+		s = "SELECT " + em.ColStr + " FROM " + em.TableName + whereClause
+		s = buildSortAndPage(em.dialect, s, query, em.idColumns)
+	}
+	if lq, ok := query.(LockQuery); ok {
+		s = em.dialect.BuildLockClause(s, em.TableName, lq.GetLockMode())
+	}
+	return s, args, nil
+}
+
+// buildCursorSelect builds the SELECT statement of the keyset
+// pagination, which fetches the records after the cursor in the
+// order of the sort columns and the id.
+func (em *EntityMetadata[E]) buildCursorSelect(query Query, after string) (string, []any, error) {
+	if err := ValidateSort(query, em.columnNames()); err != nil {
+		return "", nil, err
+	}
+	whereClause, args := buildWhereClause(em.dialect, query)
+	whereClause, args = em.filterScope(whereClause, args)
+	columns := BuildCursorColumns(query.GetSort(), em.idColumns...)
+	if after != "" {
+		values, err := DecodeCursor(after, len(columns))
+		if err != nil {
+			return "", nil, err
+		}
+		condition, keysetArgs := buildKeysetCondition(em.dialect, columns, values, em.idColumns)
+		whereClause = Ternary(whereClause == "", " WHERE ", whereClause+" AND ") + condition
+		args = append(args, keysetArgs...)
+	}
+	s := "SELECT " + em.ColStr + " FROM " + em.TableName + whereClause + buildCursorSortClause(em.dialect, columns)
+	return em.dialect.BuildPageClause(s, 0, query.GetPageSize()), args, nil
+}
+
+// buildCursor encodes the values of the cursor columns of entity.
+func (em *EntityMetadata[E]) buildCursor(entity E, sort string) (string, error) {
+	rv := reflect.ValueOf(entity)
+	columns := BuildCursorColumns(sort, em.idColumns...)
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		fm, ok := em.findColumn(column.Name)
+		if !ok {
+			return "", errors.New("unknown cursor column: " + column.Name)
+		}
+		values[i] = ReadValue(rv.FieldByName(fm.Field.Name))
+	}
+	return EncodeCursor(values)
+}
+
+func (em *EntityMetadata[E]) columnNames() []string {
+	return columnNames(em.columnMetas)
+}
+
+func columnNames(fieldMetas []FieldMetadata) []string {
+	columns := make([]string, len(fieldMetas))
+	for i, fm := range fieldMetas {
+		columns[i] = fm.ColumnName
+	}
+	return columns
+}
+
+func (em *EntityMetadata[E]) findColumn(column string) (FieldMetadata, bool) {
+	for _, fm := range em.columnMetas {
+		if fm.ColumnName == column {
+			return fm, true
+		}
+	}
+	return FieldMetadata{}, false
+}
+
+// filterDeleted appends the condition to filter out the
+// soft-deleted records to the WHERE clause unless the entity
+// has no `softdelete` field or em reads them as well.
+func (em *EntityMetadata[E]) filterDeleted(whereClause string, args []any) (string, []any) {
+	return filterDeleted(Ternary(em.withDeleted, "", em.softDelete), whereClause, args)
+}
+
+func filterDeleted(column string, whereClause string, args []any) (string, []any) {
+	if column == "" {
+		return whereClause, args
+	}
+	whereClause = Ternary(whereClause == "", " WHERE ", whereClause+" AND ") + column + " = ?"
+	return whereClause, append(args, false)
+}
+
+// softDeleteColumn returns the column of the field
+// tagged by `softdelete`, or an empty string if absent.
+func softDeleteColumn(fieldMetas []FieldMetadata) string {
+	for _, md := range fieldMetas {
+		if _, ok := md.Field.Tag.Lookup("softdelete"); ok {
+			return md.ColumnName
+		}
+	}
+	return ""
+}
+
+func (em *EntityMetadata[E]) buildSelectById(id any) (string, []any, error) {
+	idArgs, err := em.buildIdArgs(id)
+	if err != nil {
+		return "", nil, err
+	}
+	whereClause, args := em.filterScope(em.whereId, idArgs)
+	return "SELECT " + em.ColStr + " FROM " + em.TableName + whereClause, args, nil
 }
 
 func (em *EntityMetadata[E]) buildCount(query Query) (string, []any) {
-	whereClause, args := BuildWhereClause(query)
+	whereClause, args := buildWhereClause(em.dialect, query)
+	whereClause, args = em.filterScope(whereClause, args)
 	sqlStr := "SELECT count(0) FROM " + em.TableName + whereClause
 	return sqlStr, args
 }
 
-func (em *EntityMetadata[E]) buildDeleteById() string {
-	return "DELETE FROM " + em.TableName + whereId
+// buildDeleteFrom builds the statement to delete the records
+// matching the WHERE clause, which marks the records as deleted
+// for the entity with a `softdelete` field.
+func (em *EntityMetadata[E]) buildDeleteFrom(whereClause string, args []any) (string, []any) {
+	if em.softDelete == "" {
+		return "DELETE FROM " + em.TableName + whereClause, args
+	}
+	return "UPDATE " + em.TableName + " SET " + em.softDelete + " = ?" + whereClause, append([]any{true}, args...)
+}
+
+func (em *EntityMetadata[E]) buildDeleteById(id any) (string, []any, error) {
+	idArgs, err := em.buildIdArgs(id)
+	if err != nil {
+		return "", nil, err
+	}
+	whereClause, args := em.filterScope(em.whereId, idArgs)
+	sqlStr, args := em.buildDeleteFrom(whereClause, args)
+	return sqlStr, args, nil
 }
 
 func (em *EntityMetadata[E]) buildDelete(query any) (string, []any, error) {
-	whereClause, args := BuildWhereClause(query)
+	whereClause, args := buildWhereClause(em.dialect, query)
 	if whereClause == "" {
-		return "", nil, errors.New("deletion of all records is restricted")
+		return "", nil, ErrNoCondition
 	}
-	sqlStr := "DELETE FROM " + em.TableName + whereClause
+	whereClause, args = em.filterScope(whereClause, args)
+	sqlStr, args := em.buildDeleteFrom(whereClause, args)
 	return sqlStr, args, nil
 }
 
@@ -90,7 +362,7 @@ func (em *EntityMetadata[E]) buildCreate(entity E) (string, []any) {
 }
 
 func (em *EntityMetadata[E]) buildCreateMulti(entities []E) (string, []any) {
-	args := make([]any, 0, len(entities)*len(em.fieldsWithoutId))
+	args := make([]any, 0, len(entities)*len(em.insertFields))
 	for _, entity := range entities {
 		args = append(args, em.buildArgs(entity)...)
 	}
@@ -98,10 +370,23 @@ func (em *EntityMetadata[E]) buildCreateMulti(entities []E) (string, []any) {
 	return createStr, args
 }
 
+func (em *EntityMetadata[E]) buildUpsert(entities []E) (string, []any) {
+	sqlStr := em.dialect.BuildUpsert(em.TableName, em.upsertColumns, em.upsertKeys, em.upsertUpdates, em.version, em.tenant, len(entities))
+	args := make([]any, 0, len(entities)*len(em.upsertColumns))
+	for _, entity := range entities {
+		if em.upsertById {
+			args = append(args, entity.GetId())
+		}
+		args = append(args, em.buildArgs(entity)...)
+	}
+	return sqlStr, args
+}
+
 func (em *EntityMetadata[E]) buildUpdate(entity E) (string, []any) {
-	args := em.buildArgs(entity)
-	args = append(args, entity.GetId())
-	return em.updateStr, args
+	args := em.readArgs(entity, em.updateFields)
+	args = append(args, em.readIdArgs(entity)...)
+	sqlStr, args := em.filterScope(em.updateStr, args)
+	return em.appendVersion(entity, sqlStr, args)
 }
 
 func (em *EntityMetadata[E]) buildPatch(entity Entity, extra int) (string, []any) {
@@ -122,43 +407,60 @@ func (em *EntityMetadata[E]) buildPatch(entity Entity, extra int) (string, []any
 	setClauses := make([]string, 0)
 
 	for _, col := range patchFields {
+		if col == em.versionField || col == em.tenantField || em.audit.IsCreated(col) {
+			continue
+		}
 		value := rv.FieldByName(col)
 		v := ReadValue(value)
 		if v != nil {
-			setClauses = append(setClauses, resolveSetClause(col))
+			setClauses = append(setClauses, em.resolveSetClause(col))
 			args = append(args, v)
 		}
 	}
+	if len(setClauses) > 0 && em.version != "" {
+		setClauses = append(setClauses, em.version+" = "+em.version+" + 1")
+	}
 	return sqlStr + strings.Join(setClauses, ", "), args
 }
 
-func resolveSetClause(fieldname string) string {
+func (em *EntityMetadata[E]) resolveSetClause(fieldname string) string {
 	if strings.HasSuffix(fieldname, "Ae") {
-		column := ConvertToColumnCase(strings.TrimSuffix(fieldname, "Ae"))
+		column := em.dialect.Quote(columnOf(em.columnMetas, strings.TrimSuffix(fieldname, "Ae")))
 		sign := " + "
 		return column + " = " + column + sign + "?"
 	}
-	return ConvertToColumnCase(fieldname) + " = ?"
+	return em.dialect.Quote(columnOf(em.columnMetas, fieldname)) + " = ?"
+}
+
+// columnOf returns the column of the field named fieldName,
+// which is converted from the name for a field not in fieldMetas.
+func columnOf(fieldMetas []FieldMetadata, fieldName string) string {
+	for _, fm := range fieldMetas {
+		if fm.Field.Name == fieldName {
+			return fm.ColumnName
+		}
+	}
+	return ConvertToColumnCase(fieldName)
 }
 
 func (em *EntityMetadata[E]) buildPatchById(entity Entity) (string, []any) {
 	sqlStr, args := em.buildPatch(entity, 1)
-	sqlStr = sqlStr + whereId
-	args = append(args, entity.GetId())
-	return sqlStr, args
+	sqlStr, args = em.filterScope(sqlStr+em.whereId, append(args, em.readIdArgs(entity)...))
+	return em.appendVersion(entity, sqlStr, args)
 }
 
 func (em *EntityMetadata[E]) buildPatchByQuery(entity E, query Query) (string, []any, error) {
-	whereClause, argsQ := BuildWhereClause(query)
+	whereClause, argsQ := buildWhereClause(em.dialect, query)
 	patchClause, argsE := em.buildPatch(entity, len(argsQ))
 
 	if strings.HasSuffix(patchClause, "SET ") {
-		return "", nil, errors.New("at least one field should be updated")
+		return "", nil, ErrNoFieldToUpdate
 	}
 	if whereClause == "" {
-		return "", nil, errors.New("deletion of all records is restricted")
+		return "", nil, ErrNoCondition
 	}
 
+	whereClause, argsQ = em.filterScope(whereClause, argsQ)
 	args := append(argsE, argsQ...)
 	sqlStr := patchClause + whereClause
 
@@ -174,7 +476,7 @@ func FormatTableByEntity(entity any) string {
 	return fmt.Sprintf(Config.TableFormat, name)
 }
 
-func buildEntityMetadata[E Entity]() EntityMetadata[E] {
+func buildEntityMetadata[E Entity](dialect DbDialect) EntityMetadata[E] {
 	entity := *new(E)
 	entityType := reflect.TypeOf(entity)
 	fieldMetas := BuildFieldMetas(entityType)
@@ -191,40 +493,137 @@ func buildEntityMetadata[E Entity]() EntityMetadata[E] {
 	}
 
 	columns := make([]string, len(columnMetas))
+	idColumns, idNames := make([]string, 0, 1), idColumnsOf(columnMetas)
+	idFields, idFieldNames := make([]FieldMetadata, 0, 1), make([]string, 0, 1)
 	columnsWithoutId := make([]string, 0, len(columnMetas))
 	fieldsWithoutId := make([]string, 0, len(columnMetas))
 
 	for i, md := range columnMetas {
-		columns[i] = md.ColumnName
-		if !md.IsId {
+		columns[i] = dialect.Quote(md.ColumnName)
+		if md.IsId {
+			idColumns = append(idColumns, columns[i])
+			idFields = append(idFields, md)
+			idFieldNames = append(idFieldNames, md.Field.Name)
+		} else {
 			fieldsWithoutId = append(fieldsWithoutId, md.Field.Name)
-			columnsWithoutId = append(columnsWithoutId, md.ColumnName)
+			columnsWithoutId = append(columnsWithoutId, columns[i])
 		}
 	}
+	// the ids assigned before the insert, i.e. a composite key, the ids
+	// by an IdGenerator and the ids not of integers, are inserted along
+	// with the other columns, while the others are generated by the database.
+	idGenerator := ResolveIdGenerator(entityType)
+	assignedId := len(idFields) > 1 || len(idFields) == 1 && (idGenerator != nil || !isIntegerType(idFields[0].Field.Type))
+	insertFields, insertColumns := fieldsWithoutId, columnsWithoutId
+	if assignedId {
+		insertFields = append(idFieldNames, fieldsWithoutId...)
+		insertColumns = append(idColumns[:len(idColumns):len(idColumns)], columnsWithoutId...)
+	}
 
-	tableName := FormatTableByEntity(entity)
+	upsertKeys := idColumns
+	if ue, ok := any(entity).(UpsertEntity); ok {
+		upsertKeys = make([]string, 0, len(ue.ConflictColumns()))
+		for _, column := range ue.ConflictColumns() {
+			upsertKeys = append(upsertKeys, dialect.Quote(column))
+		}
+	}
+	upsertById := !assignedId && len(idColumns) == 1 && contains(upsertKeys, idColumns[0])
+	upsertColumns := insertColumns
+	if upsertById {
+		upsertColumns = append([]string{idColumns[0]}, columnsWithoutId...)
+	}
+	tenant, tenantField := "", ""
+	if md, ok := FindTenantField(entityType); ok {
+		tenant, tenantField = dialect.Quote(md.ColumnName), md.Field.Name
+	}
+	table := FormatTableByEntity(entity)
+	tableName := dialect.Quote(table)
+	softDelete := softDeleteColumn(columnMetas)
+	if softDelete != "" {
+		softDelete = dialect.Quote(softDelete)
+	}
 
-	placeholders := "(?" + strings.Repeat(", ?", len(columnsWithoutId)-1) + ")"
+	placeholders := "(?" + strings.Repeat(", ?", len(insertColumns)-1) + ")"
 	createStr := "INSERT INTO " + tableName +
-		" (" + strings.Join(columnsWithoutId, ", ") + ") " +
+		" (" + strings.Join(insertColumns, ", ") + ") " +
 		"VALUES " + placeholders
 
-	set := make([]string, len(columnsWithoutId))
+	version, versionField := "", ""
+	if vf, ok := FindVersionField(entityType); ok {
+		version, versionField = dialect.Quote(columnOf(columnMetas, vf.Name)), vf.Name
+	}
+	audit := BuildAuditFields(entityType)
+	// the conflicting rows keep the creation and increase the version
+	upsertUpdates := make([]string, 0, len(columnsWithoutId))
 	for i, col := range columnsWithoutId {
-		set[i] = col + " = ?"
+		field := fieldsWithoutId[i]
+		if !contains(upsertKeys, col) && field != tenantField && field != versionField && !audit.IsCreated(field) {
+			upsertUpdates = append(upsertUpdates, col)
+		}
 	}
+	set := make([]string, 0, len(columnsWithoutId))
+	updateFields := make([]string, 0, len(fieldsWithoutId))
+	for i, col := range columnsWithoutId {
+		if fieldsWithoutId[i] == versionField {
+			set = append(set, col+" = "+col+" + 1")
+		} else if fieldsWithoutId[i] != tenantField && !audit.IsCreated(fieldsWithoutId[i]) {
+			set = append(set, col+" = ?")
+			updateFields = append(updateFields, fieldsWithoutId[i])
+		}
+	}
+	whereId := " WHERE " + strings.Join(quoteColumns(dialect, idNames), " = ? AND ") + " = ?"
 	updateStr := "UPDATE " + tableName + " SET " + strings.Join(set, ", ") + whereId
 
-	RegisterEntity(entityType.Name(), tableName)
+	// register the table unquoted to be quoted by the dialect of each query
+	emMap.Store(entityType.Name(), &metadata{TableName: table, idColumn: idNames[0]})
 	return EntityMetadata[E]{
-		metadata:        *emMap[entityType.Name()],
+		metadata:        metadata{TableName: tableName, idColumn: idNames[0]},
+		dialect:         dialect,
 		columnMetas:     columnMetas,
 		relationMetas:   relationMetas,
+		idColumns:       idNames,
+		idFields:        idFields,
+		whereId:         whereId,
 		ColStr:          strings.Join(columns, ", "),
 		fieldsWithoutId: fieldsWithoutId,
+		insertFields:    insertFields,
+		assignedId:      assignedId,
+		idGenerator:     idGenerator,
 		createStr:       createStr,
 		placeholders:    placeholders,
 		updateStr:       updateStr,
+		upsertColumns:   upsertColumns,
+		upsertKeys:      upsertKeys,
+		upsertUpdates:   upsertUpdates,
+		upsertById:      upsertById,
+		softDelete:      softDelete,
+		version:         version,
+		versionField:    versionField,
+		updateFields:    updateFields,
+		audit:           audit,
+		tenant:          tenant,
+		tenantField:     tenantField,
 		Type:            reflect.TypeOf(*new(E)),
 	}
 }
+
+func isIntegerType(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func contains(columns []string, column string) bool {
+	for _, col := range columns {
+		if col == column {
+			return true
+		}
+	}
+	return false
+}
@@ -11,6 +11,8 @@
 package rdb
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
 
@@ -21,10 +23,10 @@ import (
 
 func TestBuildStmt(t *testing.T) {
 	log.SetLevel(log.DebugLevel)
-	em := buildEntityMetadata[UserEntity]()
+	em := buildEntityMetadata[UserEntity](Dialect)
 
 	t.Run("Build with Custom Table Name", func(t *testing.T) {
-		em := buildEntityMetadata[TestEntity]()
+		em := buildEntityMetadata[TestEntity](Dialect)
 		actual := em.TableName
 		expect := "t_user"
 		if actual != expect {
@@ -33,7 +35,7 @@ func TestBuildStmt(t *testing.T) {
 	})
 
 	t.Run("Support snake_case_column", func(t *testing.T) {
-		em := buildEntityMetadata[TestEntity]()
+		em := buildEntityMetadata[TestEntity](Dialect)
 		actual := em.ColStr
 		expect := "id, username, email, mobile, create_time"
 		if actual != expect {
@@ -67,7 +69,7 @@ func TestBuildStmt(t *testing.T) {
 
 	t.Run("Build Select Statement", func(t *testing.T) {
 		query := UserQuery{IdGt: P(5), ScoreLt: P(60)}
-		actual, args := em.buildSelect(&query)
+		actual, args, _ := em.buildSelect(&query)
 		expect := "SELECT id, score, memo FROM t_user WHERE id > ? AND score < ?"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
@@ -79,7 +81,7 @@ func TestBuildStmt(t *testing.T) {
 
 	t.Run("Build Select Without Where", func(t *testing.T) {
 		query := UserQuery{}
-		actual, args := em.buildSelect(&query)
+		actual, args, _ := em.buildSelect(&query)
 		expect := "SELECT id, score, memo FROM t_user"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
@@ -91,7 +93,7 @@ func TestBuildStmt(t *testing.T) {
 
 	t.Run("Build Select with Page Clause", func(t *testing.T) {
 		query := UserQuery{PageQuery: PageQuery{Page: 1, Size: 10}}
-		actual, args := em.buildSelect(&query)
+		actual, args, _ := em.buildSelect(&query)
 		expect := "SELECT id, score, memo FROM t_user LIMIT 10 OFFSET 0"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
@@ -103,7 +105,7 @@ func TestBuildStmt(t *testing.T) {
 
 	t.Run("Build Select with Sort Clause", func(t *testing.T) {
 		query := UserQuery{PageQuery: PageQuery{Size: 5, Sort: "id"}}
-		actual, args := em.buildSelect(&query)
+		actual, args, _ := em.buildSelect(&query)
 		expect := "SELECT id, score, memo FROM t_user ORDER BY id LIMIT 5 OFFSET 0"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
@@ -164,7 +166,7 @@ func TestBuildStmt(t *testing.T) {
 
 	t.Run("Support tag subquery", func(t *testing.T) {
 		query := UserQuery{ScoreLtAvg: &UserQuery{MemoLike: P("Well")}}
-		actual, args := em.buildSelect(&query)
+		actual, args, _ := em.buildSelect(&query)
 		expect := "SELECT id, score, memo FROM t_user WHERE score < (SELECT avg(score) FROM t_user WHERE memo LIKE ?)"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
@@ -177,7 +179,7 @@ func TestBuildStmt(t *testing.T) {
 
 	t.Run("Support tag subquery with Any", func(t *testing.T) {
 		query := UserQuery{ScoreLtAny: &UserQuery{MemoLike: P("Well")}}
-		actual, args := em.buildSelect(&query)
+		actual, args, _ := em.buildSelect(&query)
 		expect := "SELECT id, score, memo FROM t_user WHERE score < ANY(SELECT score FROM t_user WHERE memo LIKE ?)"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
@@ -190,7 +192,7 @@ func TestBuildStmt(t *testing.T) {
 
 	t.Run("Support tag subquery with All", func(t *testing.T) {
 		query := UserQuery{ScoreLtAll: &UserQuery{MemoLike: P("Well")}}
-		actual, args := em.buildSelect(&query)
+		actual, args, _ := em.buildSelect(&query)
 		expect := "SELECT id, score, memo FROM t_user WHERE score < ALL(SELECT score FROM t_user WHERE memo LIKE ?)"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
@@ -203,7 +205,7 @@ func TestBuildStmt(t *testing.T) {
 
 	t.Run("Support tag select and from", func(t *testing.T) {
 		query := UserQuery{ScoreGtAvg: &UserQuery{MemoLike: P("Well")}}
-		actual, args := em.buildSelect(&query)
+		actual, args, _ := em.buildSelect(&query)
 		expect := "SELECT id, score, memo FROM t_user WHERE score > (SELECT avg(score) FROM t_user WHERE memo LIKE ?)"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
@@ -216,7 +218,7 @@ func TestBuildStmt(t *testing.T) {
 
 	t.Run("Support subquery by fieldname: ScoreInScoreOfUser", func(t *testing.T) {
 		query := UserQuery{ScoreInScoreOfUser: &UserQuery{Deleted: P(true)}}
-		actual, args := em.buildSelect(&query)
+		actual, args, _ := em.buildSelect(&query)
 		expect := "SELECT id, score, memo FROM t_user WHERE score IN (SELECT score FROM t_user WHERE deleted = ?)"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
@@ -227,11 +229,22 @@ func TestBuildStmt(t *testing.T) {
 		}
 	})
 
+	t.Run("Quote the table of the subquery by the dialect of the query", func(t *testing.T) {
+		buildEntityMetadata[UserEntity](&SQLiteDialect{})
+		em := buildEntityMetadata[UserEntity](&MySQLDialect{})
+
+		actual, _, _ := em.buildSelect(UserQuery{ScoreGtAvg: &UserQuery{}})
+		expect := "SELECT `id`, `score`, `memo` FROM `t_user` WHERE `score` > (SELECT avg(score) FROM `t_user`)"
+		if actual != expect {
+			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+		}
+	})
+
 	t.Run("Support subquery by fieldname: ScoreGtAvgScoreOfUser", func(t *testing.T) {
 		RegisterEntity("t_user", "t_user")
 
 		query := UserQuery{ScoreGtAvgScoreOfUser: &UserQuery{Deleted: P(true)}}
-		actual, args := em.buildSelect(&query)
+		actual, args, _ := em.buildSelect(&query)
 		expect := "SELECT id, score, memo FROM t_user WHERE score > (SELECT AVG(score) FROM t_user WHERE deleted = ?)"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
@@ -242,6 +255,280 @@ func TestBuildStmt(t *testing.T) {
 		}
 	})
 
+	t.Run("Build Upsert by id", func(t *testing.T) {
+		entities := []UserEntity{{Int64Id: NewInt64Id(2), Score: P(45)}, {Int64Id: NewInt64Id(9), Memo: P("New")}}
+		actual, args := em.buildUpsert(entities)
+		expect := "INSERT INTO t_user (id, score, memo) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (id) DO UPDATE SET score = excluded.score, memo = excluded.memo"
+		if actual != expect {
+			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+		}
+		if !reflect.DeepEqual(args, []any{int64(2), 45, nil, int64(9), nil, "New"}) {
+			t.Errorf("Args are not expected: %v", args)
+		}
+	})
+
+	t.Run("Build Upsert by conflict columns", func(t *testing.T) {
+		em := buildEntityMetadata[UniqueTestEntity](&PostgresDialect{})
+		actual, args := em.buildUpsert([]UniqueTestEntity{{TestEntity{Username: P("f0rb"), Email: P("f0rb@qq.com")}}})
+		expect := `INSERT INTO "t_user" ("username", "email", "mobile", "create_time") VALUES (?, ?, ?, ?)` +
+			` ON CONFLICT ("username") DO UPDATE SET "email" = EXCLUDED."email", "mobile" = EXCLUDED."mobile", "create_time" = EXCLUDED."create_time"`
+		if actual != expect {
+			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+		}
+		if !reflect.DeepEqual(args, []any{"f0rb", "f0rb@qq.com", nil, nil}) {
+			t.Errorf("Args are not expected: %v", args)
+		}
+	})
+
+	t.Run("Build soft delete and filter the soft-deleted records", func(t *testing.T) {
+		em := buildEntityMetadata[SoftDeleteUserEntity](Dialect)
+		tests := []struct {
+			name   string
+			build  func() (string, []any)
+			expect string
+			args   []any
+		}{
+			{"Delete By Id", func() (string, []any) {
+				s, args, _ := em.buildDeleteById(3)
+				return s, args
+			}, "UPDATE t_user SET deleted = ? WHERE id = ? AND deleted = ?", []any{true, 3, false}},
+			{"Update By Id", func() (string, []any) {
+				return em.buildUpdate(SoftDeleteUserEntity{Int64Id: NewInt64Id(3), Score: P(60)})
+			}, "UPDATE t_user SET score = ?, memo = ?, deleted = ? WHERE id = ? AND deleted = ?", []any{60, nil, nil, int64(3), false}},
+			{"Patch By Id", func() (string, []any) {
+				return em.buildPatchById(SoftDeleteUserEntity{Int64Id: NewInt64Id(3), Memo: P("Bad")})
+			}, "UPDATE t_user SET memo = ? WHERE id = ? AND deleted = ?", []any{"Bad", int64(3), false}},
+			{"Delete By Query", func() (string, []any) {
+				s, args, _ := em.buildDelete(UserQuery{ScoreLt: P(60)})
+				return s, args
+			}, "UPDATE t_user SET deleted = ? WHERE score < ? AND deleted = ?", []any{true, 60, false}},
+			{"Select By Id", func() (string, []any) {
+				s, args, _ := em.buildSelectById(3)
+				return s, args
+			}, "SELECT id, score, memo, deleted FROM t_user WHERE id = ? AND deleted = ?", []any{3, false}},
+			{"Count", func() (string, []any) { return em.buildCount(UserQuery{}) },
+				"SELECT count(0) FROM t_user WHERE deleted = ?", []any{false}},
+			{"Patch By Query", func() (string, []any) {
+				s, args, _ := em.buildPatchByQuery(SoftDeleteUserEntity{Memo: P("Bad")}, UserQuery{ScoreLt: P(60)})
+				return s, args
+			}, "UPDATE t_user SET memo = ? WHERE score < ? AND deleted = ?", []any{"Bad", 60, false}},
+			{"Select with the deleted", func() (string, []any) {
+				scoped, _ := em.scope(WithDeleted(context.Background()))
+				s, args, _ := scoped.buildSelect(UserQuery{ScoreLt: P(60)})
+				return s, args
+			}, "SELECT id, score, memo, deleted FROM t_user WHERE score < ?", []any{60}},
+		}
+		for _, tt := range tests {
+			actual, args := tt.build()
+			if actual != tt.expect {
+				t.Errorf("%s\nExpected: %s\nBut got : %s", tt.name, tt.expect, actual)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("%s: Args are not expected: %v", tt.name, args)
+			}
+		}
+	})
+
+	t.Run("Build Update and Patch by the version", func(t *testing.T) {
+		em := buildEntityMetadata[VersionedUserEntity](Dialect)
+		entity := VersionedUserEntity{Int64Id: NewInt64Id(2), Score: P(90), Version: P(3)}
+
+		actual, args := em.buildUpdate(entity)
+		expect := "UPDATE t_user SET score = ?, memo = ?, version = version + 1 WHERE id = ? AND version = ?"
+		if actual != expect {
+			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+		}
+		if !reflect.DeepEqual(args, []any{90, nil, int64(2), 3}) {
+			t.Errorf("Args are not expected: %v", args)
+		}
+
+		actual, args = em.buildPatchById(entity)
+		expect = "UPDATE t_user SET score = ?, version = version + 1 WHERE id = ? AND version = ?"
+		if actual != expect {
+			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+		}
+		if !reflect.DeepEqual(args, []any{90, int64(2), 3}) {
+			t.Errorf("Args are not expected: %v", args)
+		}
+
+		_, args = em.buildCreate(VersionedUserEntity{Score: P(90)})
+		if !reflect.DeepEqual(args, []any{90, nil, 0}) {
+			t.Errorf("Args are not expected: %v", args)
+		}
+	})
+
+	t.Run("Build Update without the created audit fields", func(t *testing.T) {
+		em := buildEntityMetadata[AuditedUserEntity](Dialect)
+		actual, _ := em.buildUpdate(AuditedUserEntity{})
+		expect := "UPDATE t_user SET score = ?, update_user_id = ?, update_time = ? WHERE id = ?"
+		if actual != expect {
+			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+		}
+		actual, _ = em.buildPatchById(AuditedUserEntity{Score: P(90), CreateUserId: P(2), UpdateUserId: P(3)})
+		expect = "UPDATE t_user SET score = ?, update_user_id = ? WHERE id = ?"
+		if actual != expect {
+			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+		}
+	})
+
+	t.Run("Build statements scoped by the tenant", func(t *testing.T) {
+		em := buildEntityMetadata[TenantUserEntity](Dialect)
+		em.tenantId = 7
+		entity := TenantUserEntity{Int64Id: NewInt64Id(2), Score: P(90), TenantId: P(8)}
+		tests := []struct {
+			name   string
+			build  func() (string, []any)
+			expect string
+			args   []any
+		}{
+			{"Create", func() (string, []any) { return em.buildCreate(entity) },
+				"INSERT INTO t_user (score, memo, tenant_id) VALUES (?, ?, ?)", []any{90, nil, 7}},
+			{"Select By Id", func() (string, []any) {
+				s, args, _ := em.buildSelectById(3)
+				return s, args
+			}, "SELECT id, score, memo, tenant_id FROM t_user WHERE id = ? AND tenant_id = ?", []any{3, 7}},
+			{"Count", func() (string, []any) { return em.buildCount(UserQuery{ScoreLt: P(60)}) },
+				"SELECT count(0) FROM t_user WHERE score < ? AND tenant_id = ?", []any{60, 7}},
+			{"Update", func() (string, []any) { return em.buildUpdate(entity) },
+				"UPDATE t_user SET score = ?, memo = ? WHERE id = ? AND tenant_id = ?", []any{90, nil, int64(2), 7}},
+			{"Patch By Id", func() (string, []any) { return em.buildPatchById(entity) },
+				"UPDATE t_user SET score = ? WHERE id = ? AND tenant_id = ?", []any{90, int64(2), 7}},
+			{"Patch By Query", func() (string, []any) {
+				s, args, _ := em.buildPatchByQuery(entity, UserQuery{ScoreLt: P(60)})
+				return s, args
+			}, "UPDATE t_user SET score = ? WHERE score < ? AND tenant_id = ?", []any{90, 60, 7}},
+			{"Delete By Id", func() (string, []any) {
+				s, args, _ := em.buildDeleteById(3)
+				return s, args
+			}, "DELETE FROM t_user WHERE id = ? AND tenant_id = ?", []any{3, 7}},
+		}
+		for _, tt := range tests {
+			actual, args := tt.build()
+			if actual != tt.expect {
+				t.Errorf("%s\nExpected: %s\nBut got : %s", tt.name, tt.expect, actual)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("%s: Args are not expected: %v", tt.name, args)
+			}
+		}
+	})
+
+	t.Run("Build statements by the column and id tags", func(t *testing.T) {
+		em := buildEntityMetadata[LegacyUserEntity](Dialect)
+		entity := LegacyUserEntity{UserNo: 2, Username: P("f0rb"), Score: P(90), Password: P("secret")}
+		query := LegacyUserQuery{UserNoGt: P(int64(1)), NameStart: P("f"), NameEndOr: &[]string{"b", "c"}, PasswordNull: P(true)}
+		tests := []struct {
+			name   string
+			build  func() (string, []any)
+			expect string
+			args   []any
+		}{
+			{"Create", func() (string, []any) { return em.buildCreate(entity) },
+				"INSERT INTO t_legacy_user (usr_nm, score) VALUES (?, ?)", []any{"f0rb", 90}},
+			{"Select", func() (string, []any) {
+				s, args, _ := em.buildSelect(query)
+				return s, args
+			}, "SELECT user_no, usr_nm, score FROM t_legacy_user WHERE user_no > ? AND usr_nm LIKE ? AND (usr_nm LIKE ? OR usr_nm LIKE ?)",
+				[]any{int64(1), "f%", "%b", "%c"}},
+			{"Select By Id", func() (string, []any) {
+				s, args, _ := em.buildSelectById(3)
+				return s, args
+			}, "SELECT user_no, usr_nm, score FROM t_legacy_user WHERE user_no = ?", []any{3}},
+			{"Update", func() (string, []any) { return em.buildUpdate(entity) },
+				"UPDATE t_legacy_user SET usr_nm = ?, score = ? WHERE user_no = ?", []any{"f0rb", 90, int64(2)}},
+			{"Patch By Id", func() (string, []any) { return em.buildPatchById(entity) },
+				"UPDATE t_legacy_user SET usr_nm = ?, score = ? WHERE user_no = ?", []any{"f0rb", 90, int64(2)}},
+			{"Delete By Id", func() (string, []any) {
+				s, args, _ := em.buildDeleteById(3)
+				return s, args
+			}, "DELETE FROM t_legacy_user WHERE user_no = ?", []any{3}},
+			{"Page by the id for SQL Server", func() (string, []any) {
+				em := buildEntityMetadata[LegacyUserEntity](&SQLServerDialect{})
+				s, args, _ := em.buildSelect(LegacyUserQuery{PageQuery: PageQuery{Size: 5}})
+				return s, args
+			}, "SELECT [user_no], [usr_nm], [score] FROM [t_legacy_user] ORDER BY [user_no] OFFSET 0 ROWS FETCH NEXT 5 ROWS ONLY", []any{}},
+		}
+		for _, tt := range tests {
+			actual, args := tt.build()
+			if actual != tt.expect {
+				t.Errorf("%s\nExpected: %s\nBut got : %s", tt.name, tt.expect, actual)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("%s: Args are not expected: %v", tt.name, args)
+			}
+		}
+	})
+
+	t.Run("Build statements by the composite key", func(t *testing.T) {
+		em := buildEntityMetadata[UserRoleEntity](Dialect)
+		entity := UserRoleEntity{UserId: 1, RoleId: 2, CreateUserId: P(3)}
+		tests := []struct {
+			name   string
+			build  func() (string, []any, error)
+			expect string
+			args   []any
+		}{
+			{"Create", func() (string, []any, error) {
+				s, args := em.buildCreate(entity)
+				return s, args, nil
+			}, "INSERT INTO a_user_and_role (user_id, role_id, create_user_id) VALUES (?, ?, ?)", []any{1, 2, 3}},
+			{"Select By Key", func() (string, []any, error) { return em.buildSelectById(UserRoleKey{1, 2}) },
+				"SELECT user_id, role_id, create_user_id FROM a_user_and_role WHERE user_id = ? AND role_id = ?", []any{1, 2}},
+			{"Select By Path Id", func() (string, []any, error) { return em.buildSelectById("1,2") },
+				"SELECT user_id, role_id, create_user_id FROM a_user_and_role WHERE user_id = ? AND role_id = ?", []any{1, 2}},
+			{"Update", func() (string, []any, error) {
+				s, args := em.buildUpdate(entity)
+				return s, args, nil
+			}, "UPDATE a_user_and_role SET create_user_id = ? WHERE user_id = ? AND role_id = ?", []any{3, 1, 2}},
+			{"Patch By Id", func() (string, []any, error) {
+				s, args := em.buildPatchById(entity)
+				return s, args, nil
+			}, "UPDATE a_user_and_role SET create_user_id = ? WHERE user_id = ? AND role_id = ?", []any{3, 1, 2}},
+			{"Delete By Key", func() (string, []any, error) { return em.buildDeleteById(UserRoleKey{1, 2}) },
+				"DELETE FROM a_user_and_role WHERE user_id = ? AND role_id = ?", []any{1, 2}},
+			{"Upsert", func() (string, []any, error) {
+				s, args := em.buildUpsert([]UserRoleEntity{entity})
+				return s, args, nil
+			}, "INSERT INTO a_user_and_role (user_id, role_id, create_user_id) VALUES (?, ?, ?) " +
+				"ON CONFLICT (user_id, role_id) DO UPDATE SET create_user_id = excluded.create_user_id", []any{1, 2, 3}},
+		}
+		for _, tt := range tests {
+			actual, args, err := tt.build()
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			if actual != tt.expect {
+				t.Errorf("%s\nExpected: %s\nBut got : %s", tt.name, tt.expect, actual)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("%s: Args are not expected: %v", tt.name, args)
+			}
+		}
+
+		for _, id := range []any{3, "3", "1,a", UserEntity{}} {
+			if _, _, err := em.buildSelectById(id); !errors.Is(err, ErrInvalidId) {
+				t.Errorf("\nExpected: %v\nBut got : %v", ErrInvalidId, err)
+			}
+		}
+	})
+
+	t.Run("Build statements with the id assigned before the insert", func(t *testing.T) {
+		em := buildEntityMetadata[DeviceEntity](Dialect)
+		entity := DeviceEntity{UUIDId: NewUUIDId("d-1"), Name: P("phone")}
+
+		actual, args := em.buildCreate(entity)
+		expect := "INSERT INTO t_device (id, name) VALUES (?, ?)"
+		if actual != expect || !reflect.DeepEqual(args, []any{"d-1", "phone"}) {
+			t.Errorf("\nExpected: %s\nBut got : %s %v", expect, actual, args)
+		}
+
+		actual, args = em.buildUpsert([]DeviceEntity{entity})
+		expect = "INSERT INTO t_device (id, name) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET name = excluded.name"
+		if actual != expect || !reflect.DeepEqual(args, []any{"d-1", "phone"}) {
+			t.Errorf("\nExpected: %s\nBut got : %s %v", expect, actual, args)
+		}
+	})
+
 	t.Run("Error: Build UPDATE without SET columns", func(t *testing.T) {
 		entity := UserEntity{Score: nil}
 		_, _, err := em.buildPatchByQuery(entity, UserQuery{})
@@ -13,27 +13,32 @@ package rdb
 import (
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/doytowin/goooqo/core"
 	log "github.com/sirupsen/logrus"
 )
 
-var fpMap = make(map[string]FieldProcessor)
-var fpTypeMap = make(map[reflect.Type]bool)
+// fpMap holds the FieldProcessor by the key of the query field,
+// and fpTypeMap marks the query types whose fields are registered.
+var fpMap sync.Map
+var fpTypeMap sync.Map
 
 type FieldProcessor interface {
-	Process(value reflect.Value) (string, []any)
+	Process(d DbDialect, value reflect.Value) (string, []any)
 }
 
 func buildFpKey(queryType reflect.Type, field reflect.StructField) string {
 	return queryType.PkgPath() + ":" + queryType.Name() + ":" + field.Name
 }
 
+// registerFpByType registers the FieldProcessors of the fields
+// of queryType before marking queryType as registered, so a
+// concurrent caller either registers them again or sees them all.
 func registerFpByType(queryType reflect.Type) {
-	if fpTypeMap[queryType] == true {
+	if _, ok := fpTypeMap.Load(queryType); ok {
 		return
 	}
-	fpTypeMap[queryType] = true
 	typeQuery := reflect.TypeOf((*core.Query)(nil)).Elem()
 
 	for i := 0; i < queryType.NumField(); i++ {
@@ -44,40 +49,53 @@ func registerFpByType(queryType reflect.Type) {
 		}
 
 		fpKey := buildFpKey(queryType, field)
-		if field.Type.Kind() != reflect.Ptr {
-			log.Warn("Type not supported: ", field.Type)
-		} else if strings.HasSuffix(field.Name, "Or") {
-			if field.Type.Elem().Kind() == reflect.Slice {
-				if field.Type.Elem().Elem().Kind() == reflect.Struct {
-					fpMap[fpKey] = buildFpStructArrayByOr()
-				} else {
-					fpMap[fpKey] = buildFpBasicArrayByOr(field.Name)
-				}
-			} else {
-				fpMap[fpKey] = fpForOr
+		if fp := buildFieldProcessor(field, typeQuery); fp != nil {
+			fpMap.Store(fpKey, fp)
+		}
+	}
+	fpTypeMap.Store(queryType, true)
+}
+
+func loadFieldProcessor(fpKey string) FieldProcessor {
+	fp, _ := fpMap.Load(fpKey)
+	processor, _ := fp.(FieldProcessor)
+	return processor
+}
+
+func buildFieldProcessor(field reflect.StructField, typeQuery reflect.Type) FieldProcessor {
+	if core.ColumnTag(field.Tag) == "-" {
+		return nil
+	} else if field.Type.Kind() != reflect.Ptr {
+		log.Warn("Type not supported: ", field.Type)
+	} else if strings.HasSuffix(field.Name, "Or") {
+		if field.Type.Elem().Kind() == reflect.Slice {
+			if field.Type.Elem().Elem().Kind() == reflect.Struct {
+				return buildFpStructArrayByOr()
 			}
-		} else if strings.HasSuffix(field.Name, "And") {
-			fpMap[fpKey] = fpForAnd
-		} else if field.Type.Implements(typeQuery) {
-			buildForQuery(field, fpKey)
-		} else if _, ok := field.Tag.Lookup("condition"); ok {
-			fpMap[fpKey] = buildFpCustom(field)
-		} else {
-			fpMap[fpKey] = buildFpSuffix(field.Name)
+			return buildFpBasicArrayByOr(field)
 		}
+		return fpForOr
+	} else if strings.HasSuffix(field.Name, "And") {
+		return fpForAnd
+	} else if field.Type.Implements(typeQuery) {
+		return buildForQuery(field)
+	} else if _, ok := field.Tag.Lookup("condition"); ok {
+		return buildFpCustom(field)
+	} else {
+		return buildFpSuffixByField(field, field.Name)
 	}
+	return nil
 }
 
-func buildForQuery(field reflect.StructField, fpKey string) {
+func buildForQuery(field reflect.StructField) FieldProcessor {
 	if _, ok := field.Tag.Lookup("entitypath"); ok {
-		fpMap[fpKey] = buildFpEntityPath(field)
+		return buildFpEntityPath(field)
 	} else if subqueryTag, ok := field.Tag.Lookup("subquery"); ok {
-		fpMap[fpKey] = BuildBySubqueryTag(subqueryTag, field.Name)
+		return BuildBySubqueryTag(subqueryTag, field.Name)
 	} else if _, ok := field.Tag.Lookup("select"); ok {
-		fpMap[fpKey] = BuildBySelectTag(field.Tag, field.Name)
+		return BuildBySelectTag(field.Tag, field.Name)
 	} else if match := subOfRgx.FindStringSubmatch(field.Name); len(match) > 0 {
-		fpMap[fpKey] = BuildByFieldName(match)
-	} else {
-		log.Debug("Not mapped by field processor : ", fpKey)
+		return BuildByFieldName(match)
 	}
+	return nil
 }
@@ -11,6 +11,8 @@
 package rdb
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -29,7 +31,7 @@ func BuildRelationEntityPath(field reflect.StructField) fpEntityPath {
 	return fpEntityPath{*BuildEntityPath(field)}
 }
 
-func (fp *fpEntityPath) Process(value reflect.Value) (string, []any) {
+func (fp *fpEntityPath) Process(d DbDialect, value reflect.Value) (string, []any) {
 	args := make([]any, 0)
 
 	l := len(fp.Relations)
@@ -40,12 +42,12 @@ func (fp *fpEntityPath) Process(value reflect.Value) (string, []any) {
 		sql += "SELECT " + relation.Fk2 + " FROM " + relation.At + " WHERE " + relation.Fk1 + " IN ("
 		queryValue := value.FieldByName(Capitalize(fp.Path[i]) + "Query")
 		if queryValue.IsValid() && !queryValue.IsNil() {
-			where0, args0 := BuildWhereClause(queryValue.Interface())
-			sql += "SELECT id FROM " + FormatTable(fp.Path[i]) + where0 + "\nINTERSECT "
+			where0, args0 := buildWhereClause(d, queryValue.Interface())
+			sql += "SELECT " + d.Quote(idColumnOf(fp.Path[i])) + " FROM " + FormatTable(fp.Path[i]) + where0 + "\nINTERSECT "
 			args = append(args, args0...)
 		}
 	}
-	where, args0 := BuildWhereClause(value.Interface())
+	where, args0 := buildWhereClause(d, value.Interface())
 	args = append(args, args0...)
 	e1, _, _ := strings.Cut(fp.Path[0], "->")
 	return sql + "SELECT " + fp.Base.Fk2 + " FROM " + FormatTable(e1) + where + closeParesis, args
@@ -61,17 +63,76 @@ func buildColumns(fieldMetas []FieldMetadata) string {
 	return strings.Join(columns, ", ")
 }
 
-func (fp *fpEntityPath) buildQuery(query Query) (string, []any) {
+// buildQuery builds the statement to load the related entities
+// of `size` parents at once, which selects the parent key as the
+// first column, followed by the args of query before the parent keys.
+// The related entities are sorted and paged for each parent by
+// ROW_NUMBER() when the query needs paging. The related entities
+// with a `tenant` field are limited to the tenant in ctx, and the
+// soft-deleted ones are filtered out unless ctx is WithDeleted.
+func (fp *fpEntityPath) buildQuery(ctx context.Context, d DbDialect, query Query, size int) (string, []any, error) {
 	fieldMetas := BuildFieldMetas(fp.EntityType)
+	if err := ValidateSort(query, columnNames(retainColumns(fieldMetas))); err != nil {
+		return "", nil, err
+	}
 	columns := buildColumns(fieldMetas)
 
-	s := fp.buildSql(columns)
-	and, args := BuildConditions(query, " AND ", " AND ", "")
-	s += and + BuildSortClause(query.GetSort())
-	if query.NeedPaging() {
-		s = Dialect.BuildPageClause(s, query.CalcOffset(), query.GetPageSize())
+	where, args := buildWhereClause(d, query)
+	if !IsWithDeleted(ctx) {
+		where, args = filterDeleted(softDeleteColumn(fieldMetas), where, args)
+	}
+	if md, ok := FindTenantField(fp.EntityType); ok {
+		tenantId := GetTenantId(ctx)
+		if tenantId == nil {
+			return "", nil, ErrTenantRequired
+		}
+		where = Ternary(where == "", " WHERE ", where+" AND ") + md.ColumnName + " = ?"
+		args = append(args, tenantId)
+	}
+	pk, source := fp.buildSource("(SELECT * FROM "+fp.Base.At+where+") t", size)
+
+	orderBy := buildSortClause(d, query.GetSort())
+	if orderBy == "" {
+		orderBy = buildDefaultSortClause(d, idColumnsOf(fieldMetas))
+	}
+	if !query.NeedPaging() {
+		return "SELECT " + pk + " AS pk_, " + columns + " FROM " + source + orderBy, args, nil
+	}
+	offset := query.CalcOffset()
+	s := "SELECT pk_, " + columns + " FROM (SELECT " + pk + " AS pk_, t.*, " +
+		"ROW_NUMBER() OVER (PARTITION BY " + pk + orderBy + ") AS rn_ FROM " + source + ") r" +
+		fmt.Sprintf(" WHERE rn_ > %d AND rn_ <= %d ORDER BY pk_, rn_", offset, offset+query.GetPageSize())
+	return s, args, nil
+}
+
+// parentColumn returns the column of the parent entity whose
+// value is matched with the parent key, which is the id column
+// of the parent entity unless the relation is by a foreign key.
+func (fp *fpEntityPath) parentColumn(idColumn string) string {
+	if len(fp.Relations) == 0 {
+		return fp.Base.Fk1
+	}
+	return idColumn
+}
+
+// buildSource joins the target table with the relations
+// to carry the parent key along, and returns the parent
+// key column and the joined source.
+func (fp *fpEntityPath) buildSource(target string, size int) (string, string) {
+	in := " IN (?" + strings.Repeat(", ?", size-1) + ")"
+	l := len(fp.Relations)
+	if l == 0 {
+		pk := "t." + fp.Base.Fk2
+		return pk, target + " WHERE " + pk + in
+	}
+	last := fmt.Sprintf("r%d", l-1)
+	join := "SELECT DISTINCT r0." + fp.Relations[0].Fk1 + " AS pk_, " + last + "." + fp.Relations[l-1].Fk2 +
+		" AS fk_ FROM " + fp.Relations[l-1].At + " " + last
+	for i := l - 2; i >= 0; i-- {
+		join += fmt.Sprintf(" JOIN %s r%d ON r%d.%s = r%d.%s", fp.Relations[i].At, i, i+1, fp.Relations[i+1].Fk1, i, fp.Relations[i].Fk2)
 	}
-	return s, args
+	join += " WHERE r0." + fp.Relations[0].Fk1 + in
+	return "p.pk_", target + " JOIN (" + join + ") p ON t." + fp.Base.Fk2 + " = p.fk_"
 }
 
 func (fp *fpEntityPath) buildSql(columns string) string {
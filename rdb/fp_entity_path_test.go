@@ -11,6 +11,7 @@
 package rdb
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -19,38 +20,90 @@ import (
 )
 
 func Test_fpEntityPath_buildQuery(t *testing.T) {
-	epField := reflect.TypeOf(test.UserEntity{}).Field(3)
+	userRoles := BuildRelationEntityPath(reflect.TypeOf(test.UserEntity{}).Field(3))
+	userProducts := fpEntityPath{*BuildEntityPathStr("user,user_id<-order,product")}
+	userProducts.EntityType = reflect.TypeOf(test.RoleEntity{})
+	subMenus := fpEntityPath{*BuildEntityPathStr("menu->ParentId,menu")}
+	subMenus.EntityType = reflect.TypeOf(test.MenuEntity{})
+	parentField, _ := reflect.TypeOf(test.MenuEntity{}).FieldByName("Parent")
+	parentMenu := BuildRelationEntityPath(parentField)
+	legacyUsers := fpEntityPath{*BuildEntityPathStr("legacy_user->ParentId,legacy_user")}
+	legacyUsers.EntityType = reflect.TypeOf(LegacyUserEntity{})
 	tests := []struct {
 		name  string
-		field reflect.StructField
+		fp    fpEntityPath
 		query Query
+		size  int
 		sql   string
 		args  []any
 	}{
 		{
 			"Build SELECT FROM t_role with conditions",
-			epField,
+			userRoles,
 			test.RoleQuery{Valid: P(true)},
-			"SELECT id, role_name, role_code, create_user_id FROM t_role WHERE id IN (SELECT role_id FROM a_user_and_role WHERE user_id = ?) AND valid = ?",
+			2,
+			"SELECT p.pk_ AS pk_, id, role_name, role_code, create_user_id FROM (SELECT * FROM t_role WHERE valid = ?) t" +
+				" JOIN (SELECT DISTINCT r0.user_id AS pk_, r0.role_id AS fk_ FROM a_user_and_role r0 WHERE r0.user_id IN (?, ?)) p" +
+				" ON t.id = p.fk_ ORDER BY id",
 			[]any{true},
 		},
 		{
 			"Build SELECT FROM t_role with paging and sorting",
-			epField,
-			test.RoleQuery{PageQuery: PageQuery{10, 5, "role_name,desc"}, Valid: P(true)},
-			"SELECT id, role_name, role_code, create_user_id FROM t_role WHERE id IN (SELECT role_id FROM a_user_and_role WHERE user_id = ?) AND valid = ? ORDER BY role_name DESC LIMIT 5 OFFSET 45",
+			userRoles,
+			test.RoleQuery{PageQuery: PageQuery{Page: 10, Size: 5, Sort: "role_name,desc"}, Valid: P(true)},
+			1,
+			"SELECT pk_, id, role_name, role_code, create_user_id FROM (SELECT p.pk_ AS pk_, t.*," +
+				" ROW_NUMBER() OVER (PARTITION BY p.pk_ ORDER BY role_name DESC) AS rn_ FROM (SELECT * FROM t_role WHERE valid = ?) t" +
+				" JOIN (SELECT DISTINCT r0.user_id AS pk_, r0.role_id AS fk_ FROM a_user_and_role r0 WHERE r0.user_id IN (?)) p" +
+				" ON t.id = p.fk_) r WHERE rn_ > 45 AND rn_ <= 50 ORDER BY pk_, rn_",
 			[]any{true},
 		},
+		{
+			"Join the relations for multiple hops",
+			userProducts,
+			test.RoleQuery{},
+			2,
+			"SELECT p.pk_ AS pk_, id, role_name, role_code, create_user_id FROM (SELECT * FROM t_product) t" +
+				" JOIN (SELECT DISTINCT r0.user_id AS pk_, r1.product_id AS fk_ FROM a_order_and_product r1" +
+				" JOIN t_order r0 ON r1.order_id = r0.id WHERE r0.user_id IN (?, ?)) p ON t.id = p.fk_ ORDER BY id",
+			[]any{},
+		},
+		{
+			"Select the foreign key as the parent key",
+			subMenus,
+			test.MenuQuery{PageQuery: PageQuery{Sort: "name"}},
+			3,
+			"SELECT t.parent_id AS pk_, id, parent_id, name FROM (SELECT * FROM t_menu) t WHERE t.parent_id IN (?, ?, ?) ORDER BY name",
+			[]any{},
+		},
+		{
+			"Select the id as the parent key for the single-valued relation",
+			parentMenu,
+			test.MenuQuery{},
+			2,
+			"SELECT t.id AS pk_, id, parent_id, name FROM (SELECT * FROM t_menu) t WHERE t.id IN (?, ?) ORDER BY id",
+			[]any{},
+		},
+		{
+			"Sort by the id column of the related entity",
+			legacyUsers,
+			LegacyUserQuery{},
+			1,
+			"SELECT t.parent_id AS pk_, user_no, usr_nm, score FROM (SELECT * FROM t_legacy_user) t WHERE t.parent_id IN (?) ORDER BY user_no",
+			[]any{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fp := BuildRelationEntityPath(tt.field)
-			sql, args := fp.buildQuery(tt.query)
+			sql, args, err := tt.fp.buildQuery(context.Background(), Dialect, tt.query, tt.size)
+			if err != nil {
+				t.Fatal(err)
+			}
 			if sql != tt.sql {
-				t.Errorf("buildSql()\n got : %v\n want: %v", sql, tt.sql)
+				t.Errorf("buildQuery()\n got : %v\n want: %v", sql, tt.sql)
 			}
 			if !reflect.DeepEqual(args, tt.args) {
-				t.Errorf("buildSql()\n got : %v\n want: %v", args, tt.args)
+				t.Errorf("buildQuery()\n got : %v\n want: %v", args, tt.args)
 			}
 		})
 	}
@@ -28,7 +28,7 @@ var fpForAnd = &fpMultiConditions{connect: func(conditions []string) string {
 	return strings.Join(conditions, " AND ")
 }}
 
-func (fp *fpMultiConditions) Process(value reflect.Value) (string, []any) {
-	conditions, args := buildConditions(value.Interface())
+func (fp *fpMultiConditions) Process(d DbDialect, value reflect.Value) (string, []any) {
+	conditions, args := buildConditions(d, value.Interface())
 	return fp.connect(conditions), args
 }
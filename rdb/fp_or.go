@@ -19,15 +19,15 @@ type fpBasicArrayByOr struct {
 	fpSuffix FieldProcessor
 }
 
-func buildFpBasicArrayByOr(fieldName string) FieldProcessor {
-	return &fpBasicArrayByOr{fpSuffix: buildFpSuffix(strings.TrimSuffix(fieldName, "Or"))}
+func buildFpBasicArrayByOr(field reflect.StructField) FieldProcessor {
+	return &fpBasicArrayByOr{fpSuffix: buildFpSuffixByField(field, strings.TrimSuffix(field.Name, "Or"))}
 }
 
-func (fp *fpBasicArrayByOr) Process(value reflect.Value) (string, []any) {
+func (fp *fpBasicArrayByOr) Process(d DbDialect, value reflect.Value) (string, []any) {
 	var args, arr []any
 	conditions := make([]string, value.Len())
 	for i := 0; i < value.Len(); i++ {
-		conditions[i], arr = fp.fpSuffix.Process(value.Index(i))
+		conditions[i], arr = fp.fpSuffix.Process(d, value.Index(i))
 		args = append(args, arr...)
 	}
 	return fpForOr.connect(conditions), args
@@ -41,11 +41,11 @@ func buildFpStructArrayByOr() FieldProcessor {
 	return &fpStructArrayByOr{fpForAnd}
 }
 
-func (fp *fpStructArrayByOr) Process(value reflect.Value) (condition string, args []any) {
+func (fp *fpStructArrayByOr) Process(d DbDialect, value reflect.Value) (condition string, args []any) {
 	conditions := make([]string, value.Len())
 	var arr []any
 	for i := 0; i < value.Len(); i++ {
-		conditions[i], arr = fp.fpForAnd.Process(value.Index(i))
+		conditions[i], arr = fp.fpForAnd.Process(d, value.Index(i))
 		args = append(args, arr...)
 	}
 	return fpForOr.connect(conditions), args
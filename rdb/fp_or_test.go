@@ -20,7 +20,7 @@ import (
 func TestOr(t *testing.T) {
 
 	t.Run("Build Or Condition", func(t *testing.T) {
-		actual, _ := fpForOr.Process(reflect.ValueOf(&TestQuery{Username: P("f0rb"), Email: P("f0rb")}))
+		actual, _ := fpForOr.Process(Dialect, reflect.ValueOf(&TestQuery{Username: P("f0rb"), Email: P("f0rb")}))
 		expect := "(username = ? OR email = ?)"
 		if actual != expect {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
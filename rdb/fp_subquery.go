@@ -23,22 +23,21 @@ type fpSubquery struct {
 	select_, from string
 }
 
-func (fp *fpSubquery) Process(value reflect.Value) (string, []any) {
-	where, args := BuildWhereClause(value.Interface())
-	return fp.buildCondition(where), args
+func (fp *fpSubquery) Process(d DbDialect, value reflect.Value) (string, []any) {
+	where, args := buildWhereClause(d, value.Interface())
+	return fp.Subquery(d) + where + ")", args
 }
 
-func (fp *fpSubquery) Subquery() string {
-	if em := emMap[fp.from]; em != nil {
-		fp.from = em.TableName
-	} else {
-		fp.from = core.FormatTable(core.ConvertToColumnCase(fp.from))
+// Subquery resolves the table of the registered entity or formats
+// the table by the name, and leaves fp unchanged to be shared.
+// The column and the table are quoted by d, since the registered
+// entity may be shared by the dialects of the queries.
+func (fp *fpSubquery) Subquery(d DbDialect) string {
+	from := core.FormatTable(core.ConvertToColumnCase(fp.from))
+	if em, ok := emMap.Load(fp.from); ok {
+		from = em.(*metadata).TableName
 	}
-	return fp.column + fp.sign + "(SELECT " + fp.select_ + " FROM " + fp.from
-}
-
-func (fp *fpSubquery) buildCondition(where string) string {
-	return fp.Subquery() + where + ")"
+	return d.Quote(fp.column) + fp.sign + "(SELECT " + fp.select_ + " FROM " + d.Quote(from)
 }
 
 var sqRegx = regexp.MustCompile(`(?i)(select|from)[\s:]([\w()]+)`)
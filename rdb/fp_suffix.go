@@ -13,21 +13,25 @@ package rdb
 import (
 	"bytes"
 	"reflect"
-	"regexp"
 	"strings"
 
 	. "github.com/doytowin/goooqo/core"
 )
 
 var opMap = CreateOpMap()
-var escapeRgx = regexp.MustCompile("[\\\\_%]")
 
 type operator struct {
 	name, sign string
-	process    func(value reflect.Value) (string, []any)
+	process    func(d DbDialect, value reflect.Value) (string, []any)
 	isValid    func(value reflect.Value) bool
 }
 
+func dialectFree(fn func(value reflect.Value) (string, []any)) func(DbDialect, reflect.Value) (string, []any) {
+	return func(_ DbDialect, value reflect.Value) (string, []any) {
+		return fn(value)
+	}
+}
+
 func ok(reflect.Value) bool {
 	return true
 }
@@ -71,76 +75,79 @@ func BuildArgsForIn(value reflect.Value) (string, []any) {
 }
 
 func ReadLikeValue(value reflect.Value) string {
-	s := value.String()
-	return escapeRgx.ReplaceAllString(s, "\\$0")
+	return readLikeValue(Dialect, value)
+}
+
+func readLikeValue(d DbDialect, value reflect.Value) string {
+	return d.EscapeLike(value.String())
 }
 
 func CreateOpMap() map[string]operator {
 	const Like = " LIKE "
 	const NotLike = " NOT LIKE "
 	opMap := make(map[string]operator)
-	opMap["Gt"] = operator{"Gt", " > ", ReadValueToArray, ok}
-	opMap["Ge"] = operator{"Ge", " >= ", ReadValueToArray, ok}
-	opMap["Lt"] = operator{"Lt", " < ", ReadValueToArray, ok}
-	opMap["Le"] = operator{"Le", " <= ", ReadValueToArray, ok}
-	opMap["Ne"] = operator{"Ne", " <> ", ReadValueToArray, ok}
-	opMap["Eq"] = operator{"Eq", " = ", ReadValueToArray, ok}
-	opMap["Null"] = operator{"Null", "", func(rv reflect.Value) (string, []any) {
+	opMap["Gt"] = operator{"Gt", " > ", dialectFree(ReadValueToArray), ok}
+	opMap["Ge"] = operator{"Ge", " >= ", dialectFree(ReadValueToArray), ok}
+	opMap["Lt"] = operator{"Lt", " < ", dialectFree(ReadValueToArray), ok}
+	opMap["Le"] = operator{"Le", " <= ", dialectFree(ReadValueToArray), ok}
+	opMap["Ne"] = operator{"Ne", " <> ", dialectFree(ReadValueToArray), ok}
+	opMap["Eq"] = operator{"Eq", " = ", dialectFree(ReadValueToArray), ok}
+	opMap["Null"] = operator{"Null", "", func(_ DbDialect, rv reflect.Value) (string, []any) {
 		if rv.Bool() == false {
 			return " IS NOT NULL", []any{}
 		}
 		return " IS NULL", []any{}
 	}, ok}
-	opMap["In"] = operator{"In", " IN ", BuildArgsForIn, checkValueForIn}
-	opMap["NotIn"] = operator{"NotIn", " NOT IN ", BuildArgsForIn, checkValueForIn}
-	opMap["Like"] = operator{"Like", Like, func(value reflect.Value) (string, []any) {
+	opMap["In"] = operator{"In", " IN ", dialectFree(BuildArgsForIn), checkValueForIn}
+	opMap["NotIn"] = operator{"NotIn", " NOT IN ", dialectFree(BuildArgsForIn), checkValueForIn}
+	opMap["Like"] = operator{"Like", Like, func(d DbDialect, value reflect.Value) (string, []any) {
 		s := value.String()
-		ph := resolvePlaceHolder(s)
+		ph := resolvePlaceHolder(d, s)
 		return ph, []any{s}
 	}, isNotBlank}
-	opMap["NotLike"] = operator{"NotLike", NotLike, func(value reflect.Value) (string, []any) {
+	opMap["NotLike"] = operator{"NotLike", NotLike, func(d DbDialect, value reflect.Value) (string, []any) {
 		s := value.String()
-		ph := resolvePlaceHolder(s)
+		ph := resolvePlaceHolder(d, s)
 		return ph, []any{s}
 	}, isNotBlank}
-	opMap["Contain"] = operator{"Contain", Like, func(value reflect.Value) (string, []any) {
-		escape := ReadLikeValue(value)
-		ph := resolvePlaceHolder(escape)
+	opMap["Contain"] = operator{"Contain", Like, func(d DbDialect, value reflect.Value) (string, []any) {
+		escape := readLikeValue(d, value)
+		ph := resolvePlaceHolder(d, escape)
 		return ph, []any{"%" + escape + "%"}
 	}, isNotBlank}
-	opMap["NotContain"] = operator{"NotContain", NotLike, func(value reflect.Value) (string, []any) {
-		escape := ReadLikeValue(value)
-		ph := resolvePlaceHolder(escape)
+	opMap["NotContain"] = operator{"NotContain", NotLike, func(d DbDialect, value reflect.Value) (string, []any) {
+		escape := readLikeValue(d, value)
+		ph := resolvePlaceHolder(d, escape)
 		return ph, []any{"%" + escape + "%"}
 	}, isNotBlank}
-	opMap["Start"] = operator{"Start", Like, func(value reflect.Value) (string, []any) {
-		escape := ReadLikeValue(value)
-		ph := resolvePlaceHolder(escape)
+	opMap["Start"] = operator{"Start", Like, func(d DbDialect, value reflect.Value) (string, []any) {
+		escape := readLikeValue(d, value)
+		ph := resolvePlaceHolder(d, escape)
 		return ph, []any{escape + "%"}
 	}, isNotBlank}
-	opMap["NotStart"] = operator{"NotStart", NotLike, func(value reflect.Value) (string, []any) {
-		escape := ReadLikeValue(value)
-		ph := resolvePlaceHolder(escape)
+	opMap["NotStart"] = operator{"NotStart", NotLike, func(d DbDialect, value reflect.Value) (string, []any) {
+		escape := readLikeValue(d, value)
+		ph := resolvePlaceHolder(d, escape)
 		return ph, []any{escape + "%"}
 	}, isNotBlank}
-	opMap["End"] = operator{"End", Like, func(value reflect.Value) (string, []any) {
-		escape := ReadLikeValue(value)
-		ph := resolvePlaceHolder(escape)
+	opMap["End"] = operator{"End", Like, func(d DbDialect, value reflect.Value) (string, []any) {
+		escape := readLikeValue(d, value)
+		ph := resolvePlaceHolder(d, escape)
 		return ph, []any{"%" + escape}
 	}, isNotBlank}
-	opMap["NotEnd"] = operator{"NotEnd", NotLike, func(value reflect.Value) (string, []any) {
-		escape := ReadLikeValue(value)
-		ph := resolvePlaceHolder(escape)
+	opMap["NotEnd"] = operator{"NotEnd", NotLike, func(d DbDialect, value reflect.Value) (string, []any) {
+		escape := readLikeValue(d, value)
+		ph := resolvePlaceHolder(d, escape)
 		return ph, []any{"%" + escape}
 	}, isNotBlank}
-	opMap["Rx"] = operator{"Rx", " REGEXP ", ReadValueToArray, isNotBlank}
+	opMap["Rx"] = operator{"Rx", " REGEXP ", dialectFree(ReadValueToArray), isNotBlank}
 	return opMap
 }
 
-func resolvePlaceHolder(arg string) string {
+func resolvePlaceHolder(d DbDialect, arg string) string {
 	ph := "?"
 	if strings.Contains(arg, "\\") {
-		ph = ph + " ESCAPE '\\'"
+		ph = ph + d.BuildLikeEscape()
 	}
 	return ph
 }
@@ -160,10 +167,23 @@ func buildFpSuffix(fieldName string) fpSuffix {
 	return fpSuffix{ConvertToColumnCase(fieldName), opMap["Eq"]}
 }
 
-func (fp fpSuffix) Process(value reflect.Value) (string, []any) {
+// buildFpSuffixByField builds the fpSuffix by fieldName for
+// the query field, whose `column` tag overrides the column.
+func buildFpSuffixByField(field reflect.StructField, fieldName string) fpSuffix {
+	fp := buildFpSuffix(fieldName)
+	if column := ColumnTag(field.Tag); column != "" {
+		fp.col = column
+	}
+	return fp
+}
+
+func (fp fpSuffix) Process(d DbDialect, value reflect.Value) (string, []any) {
 	if !fp.op.isValid(value) {
 		return "", []any{}
 	}
-	placeholder, args := fp.op.process(value)
-	return fp.col + fp.op.sign + placeholder, args
+	placeholder, args := fp.op.process(d, value)
+	if fp.op.name == "Rx" {
+		return d.BuildRegexp(d.Quote(fp.col), placeholder), args
+	}
+	return d.Quote(fp.col) + fp.op.sign + placeholder, args
 }
@@ -13,6 +13,7 @@ package rdb
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"reflect"
 
 	. "github.com/doytowin/goooqo/core"
@@ -27,8 +28,9 @@ type Connection interface {
 }
 
 type relationalDataAccess[E Entity] struct {
-	conn Connection
-	em   EntityMetadata[E]
+	conn    Connection
+	dialect DbDialect
+	em      EntityMetadata[E]
 }
 
 func logSqlWithArgs(sqlStr string, args []any) (string, []any) {
@@ -37,16 +39,22 @@ func logSqlWithArgs(sqlStr string, args []any) (string, []any) {
 }
 
 func NewDataAccess[E Entity](db Connection) DataAccess[E] {
+	return newDataAccess[E](db, resolveDialect(db))
+}
+
+func newDataAccess[E Entity](db Connection, dialect DbDialect) *relationalDataAccess[E] {
 	return &relationalDataAccess[E]{
-		conn: db,
-		em:   buildEntityMetadata[E](),
+		conn:    db,
+		dialect: dialect,
+		em:      buildEntityMetadata[E](dialect),
 	}
 }
 
 func NewTxDataAccess[E Entity](tm TransactionManager) TxDataAccess[E] {
+	conn := tm.GetClient().(Connection)
 	return TxDataAccess[E]{
 		TransactionManager: tm,
-		DataAccess:         NewDataAccess[E](tm.GetClient().(Connection)),
+		DataAccess:         newDataAccess[E](conn, resolveDialect(tm, conn)),
 	}
 }
 
@@ -54,59 +62,111 @@ func NewTxDataAccess[E Entity](tm TransactionManager) TxDataAccess[E] {
 // connection by Connection as return value.
 // ctx could be a TransactionContext with an active tx.
 func (da *relationalDataAccess[E]) getConn(ctx context.Context) Connection {
-	if tc, ok := ctx.(*rdbTransactionContext); ok {
+	if tc, ok := readTransactionContext(ctx); ok {
 		return tc.tx
 	}
 	return da.conn
 }
 
 func (da *relationalDataAccess[E]) Get(ctx context.Context, id any) (*E, error) {
-	sqlStr := da.em.buildSelectById()
-	rows, err := da.doQuery(ctx, sqlStr, []any{id}, 1)
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sqlStr, args, err := em.buildSelectById(id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := da.doQuery(ctx, sqlStr, args, 1)
 	if len(rows) == 1 {
 		return &rows[0], err
 	}
+	if err == nil {
+		err = ErrNotFound
+	}
 	return nil, err
 }
 
 func (da *relationalDataAccess[E]) Query(ctx context.Context, query Query) ([]E, error) {
-	sqlStr, args := da.em.buildSelect(query)
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sqlStr, args, err := em.buildSelect(query)
+	if err != nil {
+		return nil, err
+	}
 	entities, err := da.doQuery(ctx, sqlStr, args, query.GetPageSize())
 	if err == nil && len(da.em.relationMetas) > 0 {
-		da.queryRelationEntities(ctx, entities, query)
+		err = da.queryRelationEntities(ctx, entities, query)
 	}
 	return entities, err
 }
 
-func (da *relationalDataAccess[E]) doQuery(ctx context.Context, sqlStr string, args []any, size int) ([]E, error) {
+// prepare resolves the placeholders in sqlStr by the dialect
+// and prepares the statement on the connection from ctx.
+func (da *relationalDataAccess[E]) prepare(ctx context.Context, sqlStr string, args []any) (*sql.Stmt, error) {
+	sqlStr = da.dialect.ResolvePlaceholders(sqlStr)
 	logSqlWithArgs(sqlStr, args)
+	return da.getConn(ctx).PrepareContext(ctx, sqlStr)
+}
 
+func (da *relationalDataAccess[E]) doQuery(ctx context.Context, sqlStr string, args []any, size int) ([]E, error) {
 	result := make([]E, 0, size)
+	err := da.doIterate(ctx, sqlStr, args, func(entity E) error {
+		result = append(result, entity)
+		return nil
+	})
+	return result, err
+}
 
-	entity := *new(E)
+// Iterate scans the entities matching query one row at a time and
+// stops at the first error returned by fn or the cancellation of ctx.
+// The relations declared by the WithXxx fields are not loaded since
+// the connection is occupied by the rows during the iteration.
+func (da *relationalDataAccess[E]) Iterate(ctx context.Context, query Query, fn func(entity E) error) error {
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return err
+	}
+	sqlStr, args, err := em.buildSelect(query)
+	if err != nil {
+		return err
+	}
+	return da.doIterate(ctx, sqlStr, args, fn)
+}
 
-	stmt, err := da.getConn(ctx).PrepareContext(ctx, sqlStr)
-	if err == nil {
-		defer Close(stmt)
-		var rows *sql.Rows
-		rows, err = stmt.QueryContext(ctx, args...)
-		if err == nil {
-			var pointers []any
-			if mapper, ok := any(entity).(EntityMapper); ok {
-				pointers = mapper.FieldsAddr()
-			} else {
-				pointers = preparePointers(reflect.ValueOf(&entity), da.em.columnMetas)
-			}
-			for rows.Next() {
-				err = rows.Scan(pointers...)
-				if NoError(err) {
-					result = append(result, entity)
-				}
-			}
-		}
+func (da *relationalDataAccess[E]) doIterate(ctx context.Context, sqlStr string, args []any, fn func(entity E) error) error {
+	stmt, err := da.prepare(ctx, sqlStr, args)
+	if err != nil {
+		return err
+	}
+	defer Close(stmt)
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return err
 	}
+	defer Close(rows)
 
-	return result, err
+	entity := *new(E)
+	var pointers []any
+	if mapper, ok := any(entity).(EntityMapper); ok {
+		pointers = mapper.FieldsAddr()
+	} else {
+		pointers = preparePointers(reflect.ValueOf(&entity), da.em.columnMetas)
+	}
+	for rows.Next() {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = rows.Scan(pointers...); err != nil {
+			return err
+		}
+		if err = fn(entity); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
 
 func preparePointers(p reflect.Value, fieldMetas []FieldMetadata) []any {
@@ -117,7 +177,14 @@ func preparePointers(p reflect.Value, fieldMetas []FieldMetadata) []any {
 	return pointers
 }
 
-func (da *relationalDataAccess[E]) queryRelationEntities(ctx context.Context, entities []E, query Query) {
+// queryRelationEntities loads the related entities of the WithXxx
+// fields in query for all the entities by one statement per relation.
+// A slice field receives all the related entities of an entity,
+// while a pointer field receives the first one or stays nil.
+func (da *relationalDataAccess[E]) queryRelationEntities(ctx context.Context, entities []E, query Query) error {
+	if len(entities) == 0 {
+		return nil
+	}
 	elem := reflect.ValueOf(query)
 	if elem.Kind() == reflect.Ptr {
 		elem = elem.Elem()
@@ -125,49 +192,144 @@ func (da *relationalDataAccess[E]) queryRelationEntities(ctx context.Context, en
 	for _, rm := range da.em.relationMetas {
 		queryName := "With" + rm.Field.Name
 		entityQueryVal := elem.FieldByName(queryName)
-		if !entityQueryVal.IsNil() {
-			ep := fpEntityPath{*rm.EntityPath}
-			sqlStr, args := ep.buildQuery(entityQueryVal.Interface().(Query))
-
-			for i, entity := range entities {
-				relatedEntities, err := QueryRelated(ctx, da.getConn(ctx), sqlStr,
-					append([]any{entity.GetId()}, args...), ep.EntityType)
-				if NoError(err) {
-					reflect.ValueOf(&entities[i]).Elem().FieldByName(rm.Field.Name).Set(relatedEntities)
+		if !entityQueryVal.IsValid() || entityQueryVal.IsNil() {
+			continue
+		}
+		ep := fpEntityPath{*rm.EntityPath}
+		keys, parentKeys := da.readParentKeys(entities, ep.parentColumn(da.em.idColumns[0]))
+		relatedMap := map[string]reflect.Value{}
+		if len(parentKeys) > 0 {
+			sqlStr, args, err := ep.buildQuery(ctx, da.dialect, entityQueryVal.Interface().(Query), len(parentKeys))
+			if err != nil {
+				return err
+			}
+			sqlStr = da.dialect.ResolvePlaceholders(sqlStr)
+			relatedMap, err = queryRelatedByKey(ctx, da.getConn(ctx), sqlStr, append(args, parentKeys...), ep.EntityType)
+			if err != nil {
+				return err
+			}
+		}
+		for i, key := range keys {
+			field := reflect.ValueOf(&entities[i]).Elem().FieldByName(rm.Field.Name)
+			related, ok := relatedMap[key]
+			if field.Kind() == reflect.Ptr {
+				if ok {
+					pRelated := reflect.New(ep.EntityType)
+					pRelated.Elem().Set(related.Index(0))
+					field.Set(pRelated)
+				} else {
+					field.Set(reflect.Zero(field.Type()))
 				}
+			} else if ok {
+				field.Set(related)
+			} else {
+				field.Set(reflect.MakeSlice(field.Type(), 0, 0))
 			}
 		}
 	}
+	return nil
 }
 
-func QueryRelated(ctx context.Context, conn Connection, sqlStr string, args []any, entityType reflect.Type) (reflect.Value, error) {
+// readParentKeys reads the values of the column from the entities
+// as the keys to match the related entities, and returns the distinct
+// values as the args. The key of an entity without the value is empty.
+func (da *relationalDataAccess[E]) readParentKeys(entities []E, column string) ([]string, []any) {
+	keys := make([]string, len(entities))
+	args := make([]any, 0, len(entities))
+	seen := make(map[string]bool, len(entities))
+	fieldName := ""
+	for _, md := range da.em.columnMetas {
+		if md.ColumnName == column && !md.IsId {
+			fieldName = md.Field.Name
+		}
+	}
+	for i := range entities {
+		var value any
+		if fieldName == "" {
+			value = entities[i].GetId()
+		} else if v := reflect.Indirect(reflect.ValueOf(&entities[i]).Elem().FieldByName(fieldName)); v.IsValid() {
+			value = v.Interface()
+		}
+		if value == nil {
+			continue
+		}
+		keys[i] = formatKey(value)
+		if !seen[keys[i]] {
+			seen[keys[i]] = true
+			args = append(args, value)
+		}
+	}
+	return keys, args
+}
+
+// queryRelatedByKey groups the related entities by the parent key
+// selected in the first column in front of the entity columns.
+func queryRelatedByKey(ctx context.Context, conn Connection, sqlStr string, args []any, entityType reflect.Type) (map[string]reflect.Value, error) {
 	logSqlWithArgs(sqlStr, args)
+	rows, err := conn.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer Close(rows)
+
+	var key any
+	pEntity, pointers := prepareRelatedPointers(entityType)
+	pointers = append([]any{&key}, pointers...)
 
-	stmt, err := conn.PrepareContext(ctx, sqlStr)
+	result := make(map[string]reflect.Value)
+	for rows.Next() {
+		if err = rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		k := formatKey(key)
+		related, ok := result[k]
+		if !ok {
+			related = reflect.MakeSlice(reflect.SliceOf(entityType), 0, 4)
+		}
+		result[k] = reflect.Append(related, pEntity.Elem())
+	}
+	return result, rows.Err()
+}
+
+// QueryRelated queries the entities of entityType by sqlStr
+// with the placeholders resolved by the dialect bound to conn.
+func QueryRelated(ctx context.Context, conn Connection, sqlStr string, args []any, entityType reflect.Type) (reflect.Value, error) {
+	sqlStr = resolveDialect(conn).ResolvePlaceholders(sqlStr)
+	logSqlWithArgs(sqlStr, args)
 	result := reflect.MakeSlice(reflect.SliceOf(entityType), 0, 10)
-	if err == nil {
-		defer Close(stmt)
-		var rows *sql.Rows
-		rows, err = stmt.QueryContext(ctx, args...)
-		if err == nil {
-			var pointers []any
-			pEntity := reflect.New(entityType)
-			if mapper, ok := pEntity.Interface().(EntityMapper); ok {
-				pointers = mapper.FieldsAddr()
-			} else {
-				fmArr := retainColumns(BuildFieldMetas(entityType))
-				pointers = preparePointers(pEntity, fmArr)
-			}
-			for rows.Next() {
-				err = rows.Scan(pointers...)
-				if NoError(err) {
-					result = reflect.Append(result, pEntity.Elem())
-				}
-			}
+	rows, err := conn.QueryContext(ctx, sqlStr, args...)
+	if err != nil {
+		return result, err
+	}
+	defer Close(rows)
+
+	pEntity, pointers := prepareRelatedPointers(entityType)
+	for rows.Next() {
+		if err = rows.Scan(pointers...); err != nil {
+			return result, err
 		}
+		result = reflect.Append(result, pEntity.Elem())
 	}
+	return result, rows.Err()
+}
 
-	return result, err
+// prepareRelatedPointers returns a new entity of entityType
+// and the pointers to scan its columns into.
+func prepareRelatedPointers(entityType reflect.Type) (reflect.Value, []any) {
+	pEntity := reflect.New(entityType)
+	if mapper, ok := pEntity.Interface().(EntityMapper); ok {
+		return pEntity, mapper.FieldsAddr()
+	}
+	return pEntity, preparePointers(pEntity, retainColumns(BuildFieldMetas(entityType)))
+}
+
+// formatKey formats the parent key scanned from the
+// database and the id of the parent into the same string.
+func formatKey(key any) string {
+	if b, ok := key.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(key)
 }
 
 func retainColumns(fieldMetas []FieldMetadata) []FieldMetadata {
@@ -181,16 +343,32 @@ func retainColumns(fieldMetas []FieldMetadata) []FieldMetadata {
 }
 
 func (da *relationalDataAccess[E]) Count(ctx context.Context, query Query) (int64, error) {
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
 	var cnt int64
-	sqlStr, args := da.em.buildCount(query)
-	logSqlWithArgs(sqlStr, args)
-	stmt, err := da.getConn(ctx).PrepareContext(ctx, sqlStr)
+	sqlStr, args := em.buildCount(query)
+	err = da.doQueryRow(ctx, sqlStr, args, &cnt)
+	return cnt, err
+}
+
+func (da *relationalDataAccess[E]) doQueryRow(ctx context.Context, sqlStr string, args []any, dest ...any) error {
+	stmt, err := da.prepare(ctx, sqlStr, args)
 	if err == nil {
 		defer Close(stmt)
-		row := stmt.QueryRowContext(ctx, args...)
-		err = row.Scan(&cnt)
+		err = stmt.QueryRowContext(ctx, args...).Scan(dest...)
 	}
-	return cnt, err
+	return translateError(da.dialect, err)
+}
+
+// translateError wraps the error of the driver on
+// the violation of a constraint into a ConstraintError.
+func translateError(d DbDialect, err error) error {
+	if err != nil && d.IsConstraintViolation(err) {
+		return &ConstraintError{Err: err}
+	}
+	return err
 }
 
 func (da *relationalDataAccess[E]) Page(ctx context.Context, query Query) (PageList[E], error) {
@@ -202,13 +380,56 @@ func (da *relationalDataAccess[E]) Page(ctx context.Context, query Query) (PageL
 	return PageList[E]{List: data, Total: cnt}, err
 }
 
+// CursorPage pages by the cursor in query, or fetches the first
+// page when query is not a CursorQuery or the cursor is absent.
+// The cursor of the next page is returned when the page is full.
+func (da *relationalDataAccess[E]) CursorPage(ctx context.Context, query Query) (CursorPage[E], error) {
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return CursorPage[E]{}, err
+	}
+	after, _ := ReadAfter(query)
+	sqlStr, args, err := em.buildCursorSelect(query, after)
+	if err != nil {
+		return CursorPage[E]{}, err
+	}
+	page := CursorPage[E]{}
+	page.List, err = da.doQuery(ctx, sqlStr, args, query.GetPageSize())
+	if err == nil && len(da.em.relationMetas) > 0 {
+		err = da.queryRelationEntities(ctx, page.List, query)
+	}
+	if err == nil && len(page.List) == query.GetPageSize() {
+		page.Next, err = da.em.buildCursor(page.List[len(page.List)-1], query.GetSort())
+	}
+	return page, err
+}
+
 func (da *relationalDataAccess[E]) Delete(ctx context.Context, id any) (int64, error) {
-	sqlStr := da.em.buildDeleteById()
-	return parse(da.doUpdate(ctx, sqlStr, []any{id}))
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+	self, err := NewDeleteHookEntity[E](ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	sqlStr, args, err := em.buildDeleteById(id)
+	if err != nil {
+		return 0, err
+	}
+	cnt, err := parse(da.doUpdate(ctx, sqlStr, args))
+	if err == nil && cnt > 0 && self != nil {
+		err = AfterDelete(ctx, self)
+	}
+	return cnt, err
 }
 
 func (da *relationalDataAccess[E]) DeleteByQuery(ctx context.Context, query Query) (int64, error) {
-	sqlStr, args, err := da.em.buildDelete(query)
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sqlStr, args, err := em.buildDelete(query)
 	if err != nil {
 		return 0, err
 	}
@@ -216,52 +437,271 @@ func (da *relationalDataAccess[E]) DeleteByQuery(ctx context.Context, query Quer
 }
 
 func (da *relationalDataAccess[E]) doUpdate(ctx context.Context, sqlStr string, args []any) (sql.Result, error) {
-	logSqlWithArgs(sqlStr, args)
-	stmt, err := da.getConn(ctx).PrepareContext(ctx, sqlStr)
+	stmt, err := da.prepare(ctx, sqlStr, args)
 	if err == nil {
 		defer Close(stmt)
-		return stmt.ExecContext(ctx, args...)
+		var result sql.Result
+		result, err = stmt.ExecContext(ctx, args...)
+		return result, translateError(da.dialect, err)
 	}
 	return nil, err
 }
 
+// Create inserts the entity and sets the id generated by the
+// database back to it, which is returned as well. The entity
+// with the id assigned before the insert, e.g. by an IdGenerator,
+// returns its id when it is an int64, or 0 otherwise, as well as
+// the entity of a dialect unable to read back the generated id.
 func (da *relationalDataAccess[E]) Create(ctx context.Context, entity *E) (int64, error) {
-	sqlStr, args := da.em.buildCreate(*entity)
-	result, err := da.doUpdate(ctx, sqlStr, args)
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err = BeforeCreate(ctx, entity); err != nil {
+		return 0, err
+	}
+	em.audit.FillCreated(ctx, entity)
+	if err = GenerateId(entity, em.idGenerator); err != nil {
+		return 0, err
+	}
+	sqlStr, args := em.buildCreate(*entity)
 	var id int64
-	if err == nil {
-		id, err = result.LastInsertId()
+	readId := !em.assignedId && da.dialect.ReadsGeneratedId()
+	if !readId {
+		_, err = da.doUpdate(ctx, sqlStr, args)
+		id, _ = (*entity).GetId().(int64)
+	} else if returning := da.dialect.BuildReturningId(em.idColumns[0]); returning != "" {
+		err = da.doQueryRow(ctx, sqlStr+returning, args, &id)
+	} else {
+		var result sql.Result
+		result, err = da.doUpdate(ctx, sqlStr, args)
 		if err == nil {
-			err = (*entity).SetId(entity, id)
+			id, err = result.LastInsertId()
 		}
 	}
+	if err == nil && readId {
+		err = (*entity).SetId(entity, id)
+	}
+	if err == nil {
+		err = AfterCreate(ctx, entity)
+	}
 	return id, err
 }
 
+// CreateMulti inserts the entities in one statement and sets the
+// ids generated by the database back to the entities in order.
 func (da *relationalDataAccess[E]) CreateMulti(ctx context.Context, entities []E) (int64, error) {
 	if len(entities) == 0 {
 		return 0, nil
 	}
-	sqlStr, args := da.em.buildCreateMulti(entities)
-	return parse(da.doUpdate(ctx, sqlStr, args))
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i := range entities {
+		if err = BeforeCreate(ctx, &entities[i]); err != nil {
+			return 0, err
+		}
+		em.audit.FillCreated(ctx, &entities[i])
+		if err = GenerateId(&entities[i], em.idGenerator); err != nil {
+			return 0, err
+		}
+	}
+	sqlStr, args := em.buildCreateMulti(entities)
+	var cnt int64
+	if em.assignedId || !da.dialect.ReadsGeneratedId() {
+		cnt, err = parse(da.doUpdate(ctx, sqlStr, args))
+	} else {
+		cnt, err = da.createMultiWithIds(ctx, em, sqlStr, args, entities)
+	}
+	for i := 0; err == nil && i < len(entities); i++ {
+		err = AfterCreate(ctx, &entities[i])
+	}
+	return cnt, err
+}
+
+// createMultiWithIds executes the multi-row INSERT statement and
+// reads back the generated ids, either one for each row by the
+// returning clause, or only one for the statement, from which the
+// consecutive ids are counted by the dialect.
+func (da *relationalDataAccess[E]) createMultiWithIds(
+	ctx context.Context, em *EntityMetadata[E], sqlStr string, args []any, entities []E,
+) (int64, error) {
+	var ids []int64
+	cnt := int64(len(entities))
+	var err error
+	if returning := da.dialect.BuildReturningId(em.idColumns[0]); returning != "" {
+		ids, err = da.doQueryIds(ctx, sqlStr+returning, args)
+	} else {
+		var result sql.Result
+		var id int64
+		if result, err = da.doUpdate(ctx, sqlStr, args); err == nil {
+			cnt, _ = result.RowsAffected()
+			id, err = result.LastInsertId()
+			ids = []int64{id}
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return cnt, sql.ErrNoRows
+	}
+	if len(ids) != len(entities) {
+		first := da.dialect.FirstInsertId(ids[len(ids)-1], len(entities))
+		ids = ids[:0]
+		for i := range entities {
+			ids = append(ids, first+int64(i))
+		}
+	}
+	for i := 0; err == nil && i < len(entities); i++ {
+		err = entities[i].SetId(&entities[i], ids[i])
+	}
+	return cnt, err
+}
+
+func (da *relationalDataAccess[E]) doQueryIds(ctx context.Context, sqlStr string, args []any) ([]int64, error) {
+	stmt, err := da.prepare(ctx, sqlStr, args)
+	if err != nil {
+		return nil, err
+	}
+	defer Close(stmt)
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, translateError(da.dialect, err)
+	}
+	defer Close(rows)
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, translateError(da.dialect, rows.Err())
 }
 
 func (da *relationalDataAccess[E]) Update(ctx context.Context, entity E) (int64, error) {
-	sqlStr, args := da.em.buildUpdate(entity)
-	return parse(da.doUpdate(ctx, sqlStr, args))
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err = BeforeUpdate(ctx, &entity); err != nil {
+		return 0, err
+	}
+	em.audit.FillUpdated(ctx, &entity)
+	sqlStr, args := em.buildUpdate(entity)
+	cnt, err := parse(da.doUpdate(ctx, sqlStr, args))
+	if cnt, err = da.checkVersion(entity, cnt, err); err == nil {
+		err = AfterUpdate(ctx, &entity)
+	}
+	return cnt, err
 }
 
 func (da *relationalDataAccess[E]) Patch(ctx context.Context, entity Entity) (int64, error) {
-	sqlStr, args := da.em.buildPatchById(entity)
-	return parse(da.doUpdate(ctx, sqlStr, args))
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+	self := reflect.New(reflect.TypeOf(entity))
+	self.Elem().Set(reflect.ValueOf(entity))
+	if err = BeforeUpdate(ctx, self.Interface()); err != nil {
+		return 0, err
+	}
+	em.audit.FillUpdated(ctx, self.Interface())
+	entity = self.Elem().Interface().(Entity)
+	sqlStr, args := em.buildPatchById(entity)
+	cnt, err := parse(da.doUpdate(ctx, sqlStr, args))
+	if cnt, err = da.checkVersion(entity, cnt, err); err == nil {
+		err = AfterUpdate(ctx, self.Interface())
+	}
+	return cnt, err
+}
+
+// checkVersion reports ErrOptimisticLock when the
+// update checked by the version affects no record.
+func (da *relationalDataAccess[E]) checkVersion(entity any, cnt int64, err error) (int64, error) {
+	if err == nil && cnt == 0 && da.em.readVersion(entity) != nil {
+		return 0, ErrOptimisticLock
+	}
+	return cnt, err
 }
 
 func (da *relationalDataAccess[E]) PatchByQuery(ctx context.Context, entity E, query Query) (int64, error) {
-	sqlStr, args, err := da.em.buildPatchByQuery(entity, query)
+	em, err := da.em.scope(ctx)
 	if err != nil {
 		return 0, err
 	}
-	return parse(da.doUpdate(ctx, sqlStr, args))
+	if err = BeforeUpdate(ctx, &entity); err != nil {
+		return 0, err
+	}
+	em.audit.FillUpdated(ctx, &entity)
+	sqlStr, args, err := em.buildPatchByQuery(entity, query)
+	if err != nil {
+		return 0, err
+	}
+	cnt, err := parse(da.doUpdate(ctx, sqlStr, args))
+	if err == nil {
+		err = AfterUpdate(ctx, &entity)
+	}
+	return cnt, err
+}
+
+func (da *relationalDataAccess[E]) Upsert(ctx context.Context, entity E) (int64, error) {
+	return da.UpsertMulti(ctx, []E{entity})
+}
+
+// UpsertMulti inserts the entities prepared as by Create, and
+// updates the conflicting rows instead except the audit fields
+// of the creation, which increases the version of the rows.
+// The entities without an id conflict with no rows when upserted
+// by the id, and are created to set the generated ids back.
+func (da *relationalDataAccess[E]) UpsertMulti(ctx context.Context, entities []E) (int64, error) {
+	if len(entities) == 0 {
+		return 0, nil
+	}
+	em, err := da.em.scope(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var cnt int64
+	upserted := make([]int, 0, len(entities))
+	for i := range entities {
+		if em.upsertById && isZeroId(entities[i].GetId()) {
+			if _, err = da.Create(ctx, &entities[i]); err != nil {
+				return cnt, err
+			}
+			cnt++
+			continue
+		}
+		if err = BeforeCreate(ctx, &entities[i]); err != nil {
+			return cnt, err
+		}
+		em.audit.FillCreated(ctx, &entities[i])
+		if err = GenerateId(&entities[i], em.idGenerator); err != nil {
+			return cnt, err
+		}
+		upserted = append(upserted, i)
+	}
+	if len(upserted) == 0 {
+		return cnt, nil
+	}
+	upserts := make([]E, len(upserted))
+	for j, i := range upserted {
+		upserts[j] = entities[i]
+	}
+	sqlStr, args := em.buildUpsert(upserts)
+	n, err := parse(da.doUpdate(ctx, sqlStr, args))
+	for j := 0; err == nil && j < len(upserted); j++ {
+		err = AfterCreate(ctx, &entities[upserted[j]])
+	}
+	return cnt + n, err
+}
+
+func isZeroId(id any) bool {
+	return id == nil || reflect.ValueOf(id).IsZero()
 }
 
 func parse(result sql.Result, err error) (int64, error) {
@@ -11,8 +11,11 @@
 package rdb
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/doytowin/goooqo/core"
@@ -46,12 +49,192 @@ func (e TestEntity) SetId(self any, id any) (err error) {
 	return
 }
 
+type UniqueTestEntity struct {
+	TestEntity
+}
+
+func (e UniqueTestEntity) ConflictColumns() []string {
+	return []string{"username"}
+}
+
+type SoftDeleteUserEntity struct {
+	Int64Id
+	Score   *int
+	Memo    *string
+	Deleted *bool `softdelete:"" json:"-"`
+}
+
+func (e SoftDeleteUserEntity) GetTableName() string {
+	return "t_user"
+}
+
+type AuditedUserEntity struct {
+	Int64Id
+	Score        *int
+	CreateUserId *int       `createdBy:""`
+	UpdateUserId *int       `updatedBy:""`
+	CreateTime   *time.Time `createdAt:""`
+	UpdateTime   *time.Time `updatedAt:""`
+}
+
+func (e AuditedUserEntity) GetTableName() string {
+	return "t_user"
+}
+
+type TenantUserEntity struct {
+	Int64Id
+	Score    *int
+	Memo     *string
+	TenantId *int `tenant:""`
+}
+
+func (e TenantUserEntity) GetTableName() string {
+	return "t_user"
+}
+
+// LegacyUserEntity maps the legacy columns by the tags.
+type LegacyUserEntity struct {
+	UserNo   int64   `id:"" column:"user_no"`
+	Username *string `column:"usr_nm"`
+	Score    *int
+	Password *string `column:"-"`
+}
+
+func (e LegacyUserEntity) GetTableName() string {
+	return "t_legacy_user"
+}
+
+func (e LegacyUserEntity) GetId() any {
+	return e.UserNo
+}
+
+func (e LegacyUserEntity) SetId(self any, id any) error {
+	self.(*LegacyUserEntity).UserNo = id.(int64)
+	return nil
+}
+
+// GroupEntity is identified by the group_no column,
+// which is selected when the group is in an entity path.
+type GroupEntity struct {
+	GroupNo int64 `id:"" column:"group_no"`
+	Name    *string
+}
+
+func (e GroupEntity) GetId() any {
+	return e.GroupNo
+}
+
+func (e GroupEntity) SetId(self any, id any) error {
+	self.(*GroupEntity).GroupNo = id.(int64)
+	return nil
+}
+
+type GroupQuery struct {
+	PageQuery
+	Name *string
+}
+
+type GroupPermQuery struct {
+	PageQuery
+	Code       *string
+	GroupQuery *GroupQuery
+}
+
+type MemberQuery struct {
+	PageQuery
+	Perm *GroupPermQuery `entitypath:"perm,group,member"`
+}
+
+type LegacyUserQuery struct {
+	PageQuery
+	UserNoGt     *int64
+	NameStart    *string   `column:"usr_nm"`
+	NameEndOr    *[]string `column:"usr_nm"`
+	PasswordNull *bool     `column:"-"`
+}
+
+// DeviceEntity is identified by the UUID generated before the insert.
+type DeviceEntity struct {
+	UUIDId
+	Name *string
+}
+
+func (e DeviceEntity) GetTableName() string {
+	return "t_device"
+}
+
+// OrderEntity is identified by the id of the registered IdGenerator.
+type OrderEntity struct {
+	Int64Id
+	Memo *string
+}
+
+func (e OrderEntity) GetTableName() string {
+	return "t_order"
+}
+
+var hookEvents []string
+
+type HookedUserEntity struct {
+	Int64Id
+	Score *int
+	Memo  *string
+}
+
+func (e HookedUserEntity) GetTableName() string {
+	return "t_user"
+}
+
+func (e *HookedUserEntity) record(ctx context.Context, event string) {
+	if _, ok := readTransactionContext(ctx); ok {
+		event += " in tx"
+	}
+	hookEvents = append(hookEvents, fmt.Sprintf("%s %d", event, e.Id))
+}
+
+func (e *HookedUserEntity) BeforeCreate(ctx context.Context) error {
+	if e.Score != nil && *e.Score < 0 {
+		return errors.New("score should not be negative")
+	}
+	if e.Memo != nil {
+		e.Memo = P(strings.TrimSpace(*e.Memo))
+	}
+	e.record(ctx, "BeforeCreate")
+	return nil
+}
+
+func (e *HookedUserEntity) AfterCreate(ctx context.Context) error {
+	e.record(ctx, "AfterCreate")
+	return nil
+}
+
+func (e *HookedUserEntity) BeforeUpdate(ctx context.Context) error {
+	e.record(ctx, "BeforeUpdate")
+	return nil
+}
+
+func (e *HookedUserEntity) AfterUpdate(ctx context.Context) error {
+	e.record(ctx, "AfterUpdate")
+	return nil
+}
+
+func (e *HookedUserEntity) BeforeDelete(ctx context.Context) error {
+	e.record(ctx, "BeforeDelete")
+	return nil
+}
+
+func (e *HookedUserEntity) AfterDelete(ctx context.Context) error {
+	e.record(ctx, "AfterDelete")
+	return nil
+}
+
 type TestQuery struct {
 	PageQuery
 	Username   *string
 	Email      *string
 	EmailStart *string
 	EmailNull  *bool
+	EmailRx    *string
 	Mobile     *string
 	Or         *TestQuery
 	And        *TestQuery
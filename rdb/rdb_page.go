@@ -16,17 +16,100 @@ import (
 	"github.com/doytowin/goooqo/core"
 )
 
+// buildSortAndPage appends the sort clause and the page clause of
+// query to sql, and sorts by the id columns when the query has no
+// sort but the dialect requires an ORDER BY clause for paging.
+func buildSortAndPage(d DbDialect, sql string, query core.Query, idColumns []string) string {
+	sortClause := buildSortClause(d, query.GetSort())
+	if !query.NeedPaging() {
+		return sql + sortClause
+	}
+	if sortClause == "" && d.RequireSortForPaging() {
+		sortClause = buildDefaultSortClause(d, idColumns)
+	}
+	return d.BuildPageClause(sql+sortClause, query.CalcOffset(), query.GetPageSize())
+}
+
+func buildDefaultSortClause(d DbDialect, idColumns []string) string {
+	return " ORDER BY " + strings.Join(quoteColumns(d, idColumns), ", ")
+}
+
 func BuildSortClause(sort string) string {
+	return buildSortClause(Dialect, sort)
+}
+
+// buildSortClause builds the ORDER BY clause of sort
+// with the columns quoted by d.
+func buildSortClause(d DbDialect, sort string) string {
 	if strings.TrimSpace(sort) == "" {
 		return ""
 	}
 	groups := core.SortRgx.FindAllStringSubmatch(sort, -1)
 	var orderBy = make([]string, len(groups))
 	for i, group := range groups {
-		orderBy[i] = group[1]
+		orderBy[i] = d.Quote(group[1])
 		if group[3] != "" {
 			orderBy[i] += " " + strings.ToUpper(group[3])
 		}
 	}
 	return " ORDER BY " + strings.Join(orderBy, ", ")
 }
+
+func buildCursorSortClause(d DbDialect, columns []core.SortColumn) string {
+	orderBy := make([]string, len(columns))
+	for i, column := range columns {
+		orderBy[i] = d.Quote(column.Name) + core.Ternary(column.Desc, " DESC", "")
+	}
+	return " ORDER BY " + strings.Join(orderBy, ", ")
+}
+
+// buildKeysetCondition builds the condition of the records after
+// the cursor values, such as `(score > ? OR (score = ? AND id > ?))`
+// for the columns `score;id`. The NULLs are placed where the dialect
+// sorts them, so that no records are skipped for the nullable columns
+// other than idColumns.
+func buildKeysetCondition(d DbDialect, columns []core.SortColumn, values []any, idColumns []string) (string, []any) {
+	groups := make([]string, 0, len(columns))
+	args := make([]any, 0, len(columns)*(len(columns)+1)/2)
+	for i, column := range columns {
+		afters, afterArgs := buildAfterConditions(d, column, values[i], contains(idColumns, column.Name))
+		if len(afters) == 0 {
+			continue
+		}
+		if i == 0 {
+			groups = append(groups, afters...)
+			args = append(args, afterArgs...)
+			continue
+		}
+		after := strings.Join(afters, " OR ")
+		conditions := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			column := d.Quote(columns[j].Name)
+			if values[j] == nil {
+				conditions = append(conditions, column+" IS NULL")
+			} else {
+				conditions = append(conditions, column+" = ?")
+				args = append(args, values[j])
+			}
+		}
+		conditions = append(conditions, core.Ternary(len(afters) > 1, "("+after+")", after))
+		args = append(args, afterArgs...)
+		groups = append(groups, "("+strings.Join(conditions, " AND ")+")")
+	}
+	return "(" + strings.Join(groups, " OR ") + ")", args
+}
+
+// buildAfterConditions builds the alternative conditions of the values
+// of column after value, which are none when the NULLs are sorted last.
+func buildAfterConditions(d DbDialect, column core.SortColumn, value any, notNull bool) ([]string, []any) {
+	name := d.Quote(column.Name)
+	nullsFirst := d.NullsFirst() != column.Desc
+	if value == nil {
+		return core.Ternary(nullsFirst, []string{name + " IS NOT NULL"}, nil), []any{}
+	}
+	after := name + core.Ternary(column.Desc, " < ?", " > ?")
+	if nullsFirst || notNull {
+		return []string{after}, []any{value}
+	}
+	return []string{after, name + " IS NULL"}, []any{value}
+}
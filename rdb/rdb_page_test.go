@@ -11,7 +11,10 @@
 package rdb
 
 import (
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	. "github.com/doytowin/goooqo/core"
 )
@@ -66,3 +69,100 @@ func TestBuildPageClause(t *testing.T) {
 		}
 	})
 }
+
+func TestBuildCursorSelect(t *testing.T) {
+	em := buildEntityMetadata[TestEntity](Dialect)
+	after, _ := EncodeCursor([]any{"f0rb", 5})
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	afterTime, _ := EncodeCursor([]any{created, 5})
+	afterNull, _ := EncodeCursor([]any{nil, 5})
+
+	tests := []struct {
+		name   string
+		query  Query
+		expect string
+		args   []any
+	}{
+		{
+			"Sort by id for the first page",
+			&TestQuery{PageQuery: PageQuery{Size: 5, After: P("")}},
+			"SELECT id, username, email, mobile, create_time FROM t_user ORDER BY id LIMIT 5 OFFSET 0",
+			[]any{},
+		},
+		{
+			"Fetch the records after the cursor",
+			&TestQuery{PageQuery: PageQuery{Size: 5, Sort: "username,desc", After: &after}, EmailNull: P(false)},
+			"SELECT id, username, email, mobile, create_time FROM t_user WHERE email IS NOT NULL" +
+				" AND (username < ? OR username IS NULL OR (username = ? AND id > ?)) ORDER BY username DESC, id LIMIT 5 OFFSET 0",
+			[]any{"f0rb", "f0rb", int64(5)},
+		},
+		{
+			"Restore the time values of the cursor",
+			&TestQuery{PageQuery: PageQuery{Size: 5, Sort: "create_time", After: &afterTime}},
+			"SELECT id, username, email, mobile, create_time FROM t_user" +
+				" WHERE (create_time > ? OR (create_time = ? AND id > ?)) ORDER BY create_time, id LIMIT 5 OFFSET 0",
+			[]any{created, created, int64(5)},
+		},
+		{
+			"Fetch the values after the NULLs sorted first",
+			&TestQuery{PageQuery: PageQuery{Size: 5, Sort: "username", After: &afterNull}},
+			"SELECT id, username, email, mobile, create_time FROM t_user" +
+				" WHERE (username IS NOT NULL OR (username IS NULL AND id > ?)) ORDER BY username, id LIMIT 5 OFFSET 0",
+			[]any{int64(5)},
+		},
+		{
+			"Fetch the NULLs only after the NULLs sorted last",
+			&TestQuery{PageQuery: PageQuery{Size: 5, Sort: "username,desc", After: &afterNull}},
+			"SELECT id, username, email, mobile, create_time FROM t_user" +
+				" WHERE ((username IS NULL AND id > ?)) ORDER BY username DESC, id LIMIT 5 OFFSET 0",
+			[]any{int64(5)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, args, err := em.buildSelect(tt.query)
+			if !(err == nil && actual == tt.expect) {
+				t.Errorf("\nExpected: %s\nBut got : %s, %v", tt.expect, actual, err)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("\nExpected: %v\nBut got : %v", tt.args, args)
+			}
+		})
+	}
+
+	t.Run("Include the NULLs sorted last by PostgreSQL", func(t *testing.T) {
+		columns := BuildCursorColumns("memo", "id")
+		actual, args := buildKeysetCondition(&PostgresDialect{}, columns, []any{"Bad", int64(2)}, []string{"id"})
+		expect := `("memo" > ? OR "memo" IS NULL OR ("memo" = ? AND "id" > ?))`
+		if actual != expect || !reflect.DeepEqual(args, []any{"Bad", "Bad", int64(2)}) {
+			t.Errorf("\nExpected: %s\nBut got : %s, %v", expect, actual, args)
+		}
+	})
+
+	t.Run("Reject the unknown sort column", func(t *testing.T) {
+		for _, query := range []Query{
+			&TestQuery{PageQuery: PageQuery{Sort: "password"}},
+			&TestQuery{PageQuery: PageQuery{Sort: "password", After: P("")}},
+		} {
+			_, _, err := em.buildSelect(query)
+			if !errors.Is(err, ErrInvalidSort) {
+				t.Errorf("\nExpected: %v\nBut got : %v", ErrInvalidSort, err)
+			}
+		}
+	})
+
+	t.Run("Reject the malformed cursor", func(t *testing.T) {
+		query := &TestQuery{PageQuery: PageQuery{Sort: "username", After: P("bad-cursor")}}
+		_, _, err := em.buildSelect(query)
+		if err != ErrInvalidCursor {
+			t.Errorf("\nExpected: %v\nBut got : %v", ErrInvalidCursor, err)
+		}
+	})
+
+	t.Run("Build the cursor from the sort columns", func(t *testing.T) {
+		actual, err := em.buildCursor(TestEntity{Id: P(5), Username: P("f0rb")}, "username,desc")
+		if !(err == nil && actual == after) {
+			t.Errorf("\nExpected: %s\nBut got : %s, %v", after, actual, err)
+		}
+	})
+}
@@ -13,6 +13,7 @@ package rdb
 import (
 	"context"
 	"database/sql"
+	"strconv"
 	"sync/atomic"
 
 	. "github.com/doytowin/goooqo/core"
@@ -20,39 +21,73 @@ import (
 )
 
 type rdbTransactionManager struct {
-	db *sql.DB
-	sn *atomic.Value
+	db     *sql.DB
+	client Connection
+	sn     *atomic.Value
 }
 
 func NewTransactionManager(db *sql.DB) TransactionManager {
 	sn := &atomic.Value{}
 	sn.Store(int64(0))
-	return &rdbTransactionManager{db: db, sn: sn}
+	return &rdbTransactionManager{db: db, client: db, sn: sn}
+}
+
+// NewRoutingTransactionManager creates a TransactionManager starting the
+// transactions on the primary db, whose data access routes the reads
+// outside the transactions to the replicas by a RoutingConnection.
+func NewRoutingTransactionManager(db *sql.DB, replicas []Connection, selector ReplicaSelector) TransactionManager {
+	tm := NewTransactionManager(db).(*rdbTransactionManager)
+	tm.client = NewRoutingConnection(db, replicas, selector)
+	return tm
 }
 
 func (t *rdbTransactionManager) GetClient() any {
-	return t.db
+	return t.client
+}
+
+func (t *rdbTransactionManager) StartTransaction(ctx context.Context, opts ...TxOption) (TransactionContext, error) {
+	tc, _, err := t.startTransaction(ctx, BuildTxOptions(opts...))
+	return tc, err
 }
 
-func (t *rdbTransactionManager) StartTransaction(ctx context.Context) (TransactionContext, error) {
-	if tc, ok := ctx.(*rdbTransactionContext); ok {
-		return tc, nil
+// startTransaction starts the transaction by the options, and
+// reports whether the transaction in ctx is joined.
+func (t *rdbTransactionManager) startTransaction(ctx context.Context, options TxOptions) (TransactionContext, bool, error) {
+	tc, ok := readTransactionContext(ctx)
+	switch {
+	case ok && (options.Propagation == PropagationRequired || options.Propagation == PropagationMandatory):
+		return tc, true, nil
+	case ok && options.Propagation == PropagationNested:
+		name := "sp_" + strconv.FormatInt(t.fetchSn(), 10)
+		if err := tc.SavePoint(name); err != nil {
+			return nil, false, err
+		}
+		log.Debug("Start nested Tx: ", name)
+		return &rdbSavePointContext{Context: ctx, tc: tc, name: name}, false, nil
+	case !ok && options.Propagation == PropagationMandatory:
+		return nil, false, ErrNoTransaction
 	}
-	tx, err := t.db.BeginTx(ctx, nil)
+	tx, err := t.db.BeginTx(ctx, &sql.TxOptions{Isolation: options.Isolation, ReadOnly: options.ReadOnly})
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	sn := t.fetchSn()
 	log.Debug("Start Tx: ", sn)
-	return &rdbTransactionContext{Context: ctx, tx: tx, sn: sn}, nil
+	return &rdbTransactionContext{Context: ctx, tx: tx, sn: sn}, false, nil
 }
 
-func (t *rdbTransactionManager) SubmitTransaction(ctx context.Context, callback func(tc TransactionContext) error) error {
-	tc, err := t.StartTransaction(ctx)
-	if err == nil {
-		err = TransactionCallback(tc, callback)
+// SubmitTransaction commits the transaction started by the options
+// when callback succeeds, or rolls it back otherwise. A joined
+// transaction is left to its owner to commit or roll back.
+func (t *rdbTransactionManager) SubmitTransaction(ctx context.Context, callback func(tc TransactionContext) error, opts ...TxOption) error {
+	tc, joined, err := t.startTransaction(ctx, BuildTxOptions(opts...))
+	if err != nil {
+		return err
 	}
-	return err
+	if joined {
+		return callback(tc)
+	}
+	return TransactionCallback(tc, callback)
 }
 
 func (t *rdbTransactionManager) fetchSn() int64 {
@@ -65,20 +100,51 @@ func (t *rdbTransactionManager) fetchSn() int64 {
 
 type rdbTransactionContext struct {
 	context.Context
-	tx *sql.Tx
-	sn int64
+	tx        *sql.Tx
+	sn        int64
+	callbacks []func()
+}
+
+// Value returns the transaction context itself for TxKey, so the
+// transaction is still found after ctx is wrapped by context.WithValue.
+func (t *rdbTransactionContext) Value(key any) any {
+	if key == (TxKey{}) {
+		return t
+	}
+	return t.Context.Value(key)
+}
+
+// readTransactionContext reads the active transaction context from ctx.
+func readTransactionContext(ctx context.Context) (*rdbTransactionContext, bool) {
+	tc, ok := ctx.Value(TxKey{}).(*rdbTransactionContext)
+	return tc, ok
 }
 
 func (t *rdbTransactionContext) Commit() error {
 	log.Debug("Commit Tx: ", t.sn)
+	defer t.complete()
 	return t.tx.Commit()
 }
 
 func (t *rdbTransactionContext) Rollback() error {
 	log.Debug("Rollback Tx: ", t.sn)
+	defer t.complete()
 	return t.tx.Rollback()
 }
 
+func (t *rdbTransactionContext) AfterCompletion(callback func()) {
+	t.callbacks = append(t.callbacks, callback)
+}
+
+// complete invokes the callbacks registered by AfterCompletion once.
+func (t *rdbTransactionContext) complete() {
+	callbacks := t.callbacks
+	t.callbacks = nil
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
 func (t *rdbTransactionContext) Parent() context.Context {
 	return t.Context
 }
@@ -92,3 +158,35 @@ func (t *rdbTransactionContext) RollbackTo(name string) error {
 	_, err := t.tx.ExecContext(t.Context, "ROLLBACK TO SAVEPOINT "+name)
 	return err
 }
+
+// rdbSavePointContext is the nested transaction in the transaction
+// of tc, which releases the savepoint on commit and rolls back to
+// the savepoint on rollback.
+type rdbSavePointContext struct {
+	context.Context
+	tc   *rdbTransactionContext
+	name string
+}
+
+func (t *rdbSavePointContext) Commit() error {
+	log.Debug("Release nested Tx: ", t.name)
+	_, err := t.tc.tx.ExecContext(t.Context, "RELEASE SAVEPOINT "+t.name)
+	return err
+}
+
+func (t *rdbSavePointContext) Rollback() error {
+	log.Debug("Rollback nested Tx: ", t.name)
+	return t.tc.RollbackTo(t.name)
+}
+
+func (t *rdbSavePointContext) Parent() context.Context {
+	return t.Context
+}
+
+func (t *rdbSavePointContext) SavePoint(name string) error {
+	return t.tc.SavePoint(name)
+}
+
+func (t *rdbSavePointContext) RollbackTo(name string) error {
+	return t.tc.RollbackTo(name)
+}
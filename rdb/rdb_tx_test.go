@@ -12,6 +12,8 @@ package rdb
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"testing"
 
 	. "github.com/doytowin/goooqo/core"
@@ -48,6 +50,107 @@ func TestTx(t *testing.T) {
 		}
 	})
 
+	t.Run("Should find tx in the context wrapping TransactionContext", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer tc.Rollback()
+
+		wrapped := WithUserId(tc, 1)
+		if tc2, _ := tm.StartTransaction(wrapped); tc2 != tc {
+			t.Error("Should not start tx repeated")
+		}
+		if cnt, _ := userDataAccess.Delete(wrapped, 1); cnt != 1 {
+			t.Error("Should delete in tx: ", cnt)
+		}
+		if cnt, _ := userDataAccess.Count(tc, UserQuery{}); cnt != 3 {
+			t.Error("Should count in tx: ", cnt)
+		}
+	})
+
+	t.Run("Join the existing transaction without commit", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer tc.Rollback()
+
+		err := tm.SubmitTransaction(tc, func(tc2 TransactionContext) error {
+			if tc2 != tc {
+				t.Error("Should join the existing tx")
+			}
+			_, err := userDataAccess.Delete(tc2, 1)
+			return err
+		}, WithPropagation(PropagationMandatory))
+		if err != nil {
+			t.Fatal(err)
+		}
+		NoError(tc.Rollback())
+		if cnt, _ := userDataAccess.Count(ctx, UserQuery{}); cnt != 4 {
+			t.Error("Should be rolled back by the owner: ", cnt)
+		}
+	})
+
+	t.Run("Require an existing transaction for PropagationMandatory", func(t *testing.T) {
+		err := tm.SubmitTransaction(ctx, func(tc TransactionContext) error {
+			return nil
+		}, WithPropagation(PropagationMandatory))
+		if err != ErrNoTransaction {
+			t.Errorf("\nExpected: %v\nBut got : %v", ErrNoTransaction, err)
+		}
+	})
+
+	t.Run("Roll back the nested transaction to the savepoint", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer tc.Rollback()
+
+		nested := WithPropagation(PropagationNested)
+		err := tm.SubmitTransaction(tc, func(tc2 TransactionContext) error {
+			_, _ = userDataAccess.Delete(tc2, 1)
+			return errors.New("rollback nested")
+		}, nested)
+		if err == nil || err.Error() != "rollback nested" {
+			t.Fatal("Should return the error of callback: ", err)
+		}
+		err = tm.SubmitTransaction(tc, func(tc2 TransactionContext) error {
+			_, err := userDataAccess.Delete(tc2, 2)
+			return err
+		}, nested)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entities, _ := userDataAccess.Query(tc, UserQuery{})
+		if !(len(entities) == 3 && entities[0].Id == 1 && entities[1].Id == 3) {
+			t.Error("Should only roll back the nested tx: ", entities)
+		}
+	})
+
+	t.Run("Start a new transaction for PropagationRequiresNew", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer tc.Rollback()
+
+		tc2, err := tm.StartTransaction(tc, WithPropagation(PropagationRequiresNew), WithIsolation(sql.LevelSerializable), WithReadOnly())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tc2.Rollback()
+		if tc2 == tc || tc2.(*rdbTransactionContext).tx == tc.(*rdbTransactionContext).tx {
+			t.Error("Should start a new tx")
+		}
+		if tc3, _ := readTransactionContext(tc2); tc3 != tc2 {
+			t.Error("Should access by the new tx")
+		}
+	})
+
+	t.Run("Invoke the callbacks after completion", func(t *testing.T) {
+		completed := 0
+		err := tm.SubmitTransaction(ctx, func(tc TransactionContext) error {
+			AfterCompletion(WithUserId(tc, 1), func() { completed++ })
+			if completed != 0 {
+				t.Error("Should not invoke before completion")
+			}
+			return nil
+		})
+		if err != nil || completed != 1 {
+			t.Errorf("Should invoke once after commit: %d, %v", completed, err)
+		}
+	})
+
 	t.Run("Support save point", func(t *testing.T) {
 		tc, _ := tm.StartTransaction(ctx)
 		defer tc.Rollback()
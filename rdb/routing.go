@@ -0,0 +1,97 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"regexp"
+	"sync/atomic"
+)
+
+// ReplicaSelector selects one of the replicas to execute a read.
+type ReplicaSelector func(ctx context.Context, replicas []Connection) Connection
+
+// RoundRobinSelector returns a ReplicaSelector
+// which selects the replicas in turn.
+func RoundRobinSelector() ReplicaSelector {
+	var next uint64
+	return func(_ context.Context, replicas []Connection) Connection {
+		n := atomic.AddUint64(&next, 1) - 1
+		return replicas[n%uint64(len(replicas))]
+	}
+}
+
+// RandomSelector selects one of the replicas at random.
+func RandomSelector(_ context.Context, replicas []Connection) Connection {
+	return replicas[rand.Intn(len(replicas))]
+}
+
+type readYourWritesKey struct{}
+
+// WithReadYourWrites returns a copy of ctx which routes the reads to
+// the primary, so that the records just written are always visible.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, true)
+}
+
+func isReadYourWrites(ctx context.Context) bool {
+	v, _ := ctx.Value(readYourWritesKey{}).(bool)
+	return v
+}
+
+var readStmtRgx = regexp.MustCompile(`(?i)^\s*SELECT\b`)
+var lockStmtRgx = regexp.MustCompile(`(?i)\b(FOR\s+(UPDATE|SHARE)|LOCK\s+IN\s+SHARE\s+MODE|UPDLOCK|HOLDLOCK)\b`)
+
+// RoutingConnection routes the SELECT statements to the replicas
+// selected by the ReplicaSelector, and the other statements, the
+// locking reads and the reads with WithReadYourWrites to the primary.
+// The statements in a transaction are executed by the transaction
+// started on the primary by the TransactionManager.
+type RoutingConnection struct {
+	primary  Connection
+	replicas []Connection
+	selector ReplicaSelector
+}
+
+// NewRoutingConnection creates a RoutingConnection, which selects
+// the replicas by RoundRobinSelector when selector is nil.
+func NewRoutingConnection(primary Connection, replicas []Connection, selector ReplicaSelector) *RoutingConnection {
+	if selector == nil {
+		selector = RoundRobinSelector()
+	}
+	return &RoutingConnection{primary: primary, replicas: replicas, selector: selector}
+}
+
+func (r *RoutingConnection) route(ctx context.Context, query string) Connection {
+	if len(r.replicas) == 0 || isReadYourWrites(ctx) ||
+		!readStmtRgx.MatchString(query) || lockStmtRgx.MatchString(query) {
+		return r.primary
+	}
+	return r.selector(ctx, r.replicas)
+}
+
+func (r *RoutingConnection) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return r.route(ctx, query).PrepareContext(ctx, query)
+}
+
+func (r *RoutingConnection) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.primary.ExecContext(ctx, query, args...)
+}
+
+func (r *RoutingConnection) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.route(ctx, query).QueryContext(ctx, query, args...)
+}
+
+func (r *RoutingConnection) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return r.route(ctx, query).QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,107 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	. "github.com/doytowin/goooqo/core"
+	. "github.com/doytowin/goooqo/test"
+)
+
+func openSQLite(t *testing.T, name string) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	InitDB(db)
+	t.Cleanup(func() { Disconnect(db) })
+	return db
+}
+
+func TestRoutingConnection(t *testing.T) {
+	ctx := context.Background()
+	primary := openSQLite(t, "primary.db")
+	replica1 := openSQLite(t, "replica1.db")
+	replica2 := openSQLite(t, "replica2.db")
+	_, _ = replica1.Exec("DELETE FROM t_user WHERE id = 4")
+	_, _ = replica2.Exec("DELETE FROM t_user WHERE id IN (3, 4)")
+
+	tm := NewRoutingTransactionManager(primary, []Connection{replica1, replica2}, nil)
+	userDataAccess := NewTxDataAccess[UserEntity](tm)
+
+	t.Run("Route the reads to the replicas in turn", func(t *testing.T) {
+		var counts []int64
+		for i := 0; i < 3; i++ {
+			cnt, _ := userDataAccess.Count(ctx, UserQuery{})
+			counts = append(counts, cnt)
+		}
+		if !(counts[0] == 3 && counts[1] == 2 && counts[2] == 3) {
+			t.Errorf("Should read from the replicas: %v", counts)
+		}
+	})
+
+	t.Run("Route the writes to the primary", func(t *testing.T) {
+		entity := UserEntity{Score: P(90)}
+		if _, err := userDataAccess.Create(ctx, &entity); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _, _ = userDataAccess.Delete(ctx, entity.Id) }()
+
+		user, err := userDataAccess.Get(WithReadYourWrites(ctx), entity.Id)
+		if err != nil || *user.Score != 90 {
+			t.Errorf("Should read your writes: %v, %v", user, err)
+		}
+		if _, err = userDataAccess.Get(ctx, entity.Id); err != ErrNotFound {
+			t.Errorf("\nExpected: %v\nBut got : %v", ErrNotFound, err)
+		}
+	})
+
+	t.Run("Route the statements in a transaction to the primary", func(t *testing.T) {
+		err := tm.SubmitTransaction(ctx, func(tc TransactionContext) error {
+			cnt, err := userDataAccess.Count(tc, UserQuery{})
+			if cnt != 4 {
+				t.Errorf("\nExpected: %d\nBut got : %d", 4, cnt)
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Select the replicas by the ReplicaSelector", func(t *testing.T) {
+		rc := NewRoutingConnection(primary, []Connection{replica1, replica2}, func(_ context.Context, replicas []Connection) Connection {
+			return replicas[1]
+		})
+		tests := []struct {
+			query  string
+			expect Connection
+		}{
+			{"SELECT count(0) FROM t_user", replica2},
+			{"  select id FROM t_user", replica2},
+			{"SELECT id FROM t_user WHERE id = ? FOR UPDATE", primary},
+			{"UPDATE t_user SET score = ? WHERE id = ?", primary},
+			{"INSERT INTO t_user (score) VALUES (?) RETURNING id", primary},
+		}
+		for _, tt := range tests {
+			if actual := rc.route(ctx, tt.query); actual != tt.expect {
+				t.Errorf("Unexpected connection for %s", tt.query)
+			}
+		}
+		if actual := rc.route(WithReadYourWrites(ctx), tests[0].query); actual != primary {
+			t.Errorf("Should route to the primary for read-your-writes")
+		}
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	. "github.com/doytowin/goooqo/core"
 	. "github.com/doytowin/goooqo/test"
@@ -56,8 +57,8 @@ func TestSQLite(t *testing.T) {
 	t.Run("Query By Non-Existent Id", func(t *testing.T) {
 		user, err := userDataAccess.Get(ctx, -1)
 
-		if err != nil {
-			t.Error("Error", err)
+		if err != ErrNotFound {
+			t.Errorf("\nExpected: %v\nBut got : %v", ErrNotFound, err)
 		}
 		if user != nil {
 			t.Errorf("Data is not expected: %v", &user)
@@ -118,6 +119,104 @@ func TestSQLite(t *testing.T) {
 		}
 	})
 
+	t.Run("Soft delete the entity with a softdelete field", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer func() { _ = tc.Rollback() }()
+		softDeleteDataAccess := NewTxDataAccess[SoftDeleteUserEntity](tm)
+
+		cnt, err := softDeleteDataAccess.Delete(tc, 2)
+		if err != nil || cnt != 1 {
+			t.Fatalf("Delete failed. Deleted: %v, %v", cnt, err)
+		}
+		cnt, err = softDeleteDataAccess.DeleteByQuery(tc, UserQuery{ScoreLt: P(60)})
+		if err != nil || cnt != 1 {
+			t.Fatalf("Delete failed. Deleted: %v, %v", cnt, err)
+		}
+		user, err := softDeleteDataAccess.Get(tc, 2)
+		if err != ErrNotFound || user != nil {
+			t.Errorf("Data is not expected: %v, %v", user, err)
+		}
+		if cnt, _ = softDeleteDataAccess.Count(tc, UserQuery{}); cnt != 2 {
+			t.Errorf("\nExpected: %d\nBut got : %d", 2, cnt)
+		}
+		if cnt, _ = userDataAccess.Count(tc, UserQuery{}); cnt != 4 {
+			t.Errorf("\nExpected: %d\nBut got : %d", 4, cnt)
+		}
+		cnt, err = softDeleteDataAccess.PatchByQuery(tc, SoftDeleteUserEntity{Memo: P("Kept")}, UserQuery{ScoreLt: P(100)})
+		if err != nil || cnt != 2 {
+			t.Errorf("PatchByQuery failed. Patched: %v, %v", cnt, err)
+		}
+		cnt, err = softDeleteDataAccess.Delete(tc, 2)
+		if err != nil || cnt != 0 {
+			t.Errorf("Delete the deleted. Deleted: %v, %v", cnt, err)
+		}
+		cnt, err = softDeleteDataAccess.Update(tc, SoftDeleteUserEntity{Int64Id: NewInt64Id(2)})
+		if err != nil || cnt != 0 {
+			t.Errorf("Update the deleted. Updated: %v, %v", cnt, err)
+		}
+		cnt, err = softDeleteDataAccess.Patch(tc, SoftDeleteUserEntity{Int64Id: NewInt64Id(2), Memo: P("Kept")})
+		if err != nil || cnt != 0 {
+			t.Errorf("Patch the deleted. Patched: %v, %v", cnt, err)
+		}
+		cnt, err = softDeleteDataAccess.Patch(WithDeleted(tc), SoftDeleteUserEntity{Int64Id: NewInt64Id(2), Memo: P("Kept")})
+		if err != nil || cnt != 1 {
+			t.Errorf("Patch with the deleted failed. Patched: %v, %v", cnt, err)
+		}
+		users, _ := softDeleteDataAccess.Query(WithDeleted(tc), UserQuery{Deleted: P(true)})
+		if len(users) != 2 || users[0].Id != 2 || users[1].Id != 3 {
+			t.Errorf("Data is not expected: %v", users)
+		}
+	})
+
+	t.Run("Scope the entity with a tenant field by the tenant in ctx", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer func() { _ = tc.Rollback() }()
+		tenantDataAccess := NewTxDataAccess[TenantUserEntity](tm)
+
+		if _, err := tenantDataAccess.Count(tc, UserQuery{}); err != ErrTenantRequired {
+			t.Fatalf("\nExpected: %v\nBut got : %v", ErrTenantRequired, err)
+		}
+		tenant1, tenant2 := WithTenantId(tc, 1), WithTenantId(tc, 2)
+		entity := TenantUserEntity{Score: P(70), TenantId: P(1)}
+		if _, err := tenantDataAccess.Create(tenant2, &entity); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if cnt, _ := tenantDataAccess.Count(tenant1, UserQuery{}); cnt != 4 {
+			t.Errorf("\nExpected: %d\nBut got : %d", 4, cnt)
+		}
+		users, _ := tenantDataAccess.Query(tenant2, UserQuery{})
+		if len(users) != 1 || users[0].Id != entity.Id || *users[0].TenantId != 2 {
+			t.Fatalf("Data is not expected: %v", users)
+		}
+		if user, err := tenantDataAccess.Get(tenant2, 1); err != ErrNotFound || user != nil {
+			t.Errorf("Data is not expected: %v, %v", user, err)
+		}
+		cnt, err := tenantDataAccess.Update(tenant2, TenantUserEntity{Int64Id: NewInt64Id(1), Score: P(10)})
+		if err != nil || cnt != 0 {
+			t.Errorf("Update failed. Updated: %v, %v", cnt, err)
+		}
+		cnt, err = tenantDataAccess.Patch(tenant1, TenantUserEntity{Int64Id: NewInt64Id(1), Score: P(10), TenantId: P(2)})
+		if err != nil || cnt != 1 {
+			t.Errorf("Patch failed. Patched: %v, %v", cnt, err)
+		}
+		hacked := TenantUserEntity{Int64Id: NewInt64Id(1), Score: P(999), Memo: P("hacked")}
+		if _, err = tenantDataAccess.Upsert(tenant2, hacked); err != nil {
+			t.Errorf("Upsert failed: %v", err)
+		}
+		if user, err := tenantDataAccess.Get(tenant1, 1); err != nil || *user.Score != 10 || *user.Memo == "hacked" {
+			t.Errorf("Data is not expected: %v, %v", user, err)
+		}
+		if cnt, _ = tenantDataAccess.DeleteByQuery(tenant2, UserQuery{ScoreLt: P(60)}); cnt != 0 {
+			t.Errorf("\nExpected: %d\nBut got : %d", 0, cnt)
+		}
+		if cnt, _ = tenantDataAccess.Delete(tenant1, entity.Id); cnt != 0 {
+			t.Errorf("\nExpected: %d\nBut got : %d", 0, cnt)
+		}
+		if cnt, _ = tenantDataAccess.Count(tenant1, UserQuery{ScoreLt: P(60)}); cnt != 3 {
+			t.Errorf("\nExpected: %d\nBut got : %d", 3, cnt)
+		}
+	})
+
 	t.Run("Count By Query", func(t *testing.T) {
 		userQuery := UserQuery{ScoreLt: P(60)}
 		cnt, err := userDataAccess.Count(ctx, &userQuery)
@@ -169,9 +268,112 @@ func TestSQLite(t *testing.T) {
 		if !(cnt == 2) {
 			t.Errorf("\nExpected: %d\nBut got : %d", 2, cnt)
 		}
+		if !(entities[0].Id == 5 && entities[1].Id == 6) {
+			t.Errorf("\nExpected: %d, %d\nBut got : %d, %d", 5, 6, entities[0].Id, entities[1].Id)
+		}
+		if actual, err := userDataAccess.Get(tc, entities[1].Id); err != nil || *actual.Memo != "Bad" {
+			t.Errorf("Data is not expected: %v, %v", actual, err)
+		}
 		_ = tc.Rollback()
 	})
 
+	t.Run("Translate the constraint violation", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer func() { _ = tc.Rollback() }()
+		userRoleDataAccess := NewTxDataAccess[UserRoleEntity](tm)
+
+		_, err := userRoleDataAccess.Create(tc, &UserRoleEntity{UserId: 1, RoleId: 1})
+		if !errors.Is(err, ErrConstraintViolation) {
+			t.Errorf("\nExpected: %v\nBut got : %v", ErrConstraintViolation, err)
+		}
+	})
+
+	t.Run("Map the legacy columns by the tags", func(t *testing.T) {
+		_, err := db.Exec("create table t_legacy_user(user_no integer primary key autoincrement, usr_nm varchar(30), score integer)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _, _ = db.Exec("drop table t_legacy_user") }()
+		legacyDataAccess := NewTxDataAccess[LegacyUserEntity](tm)
+
+		entity := LegacyUserEntity{Username: P("f0rb"), Score: P(90), Password: P("secret")}
+		if _, err = legacyDataAccess.Create(ctx, &entity); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := legacyDataAccess.Get(ctx, entity.UserNo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !(actual.UserNo == 1 && *actual.Username == "f0rb" && *actual.Score == 90 && actual.Password == nil) {
+			t.Errorf("Data is not expected: %v", actual)
+		}
+	})
+
+	t.Run("Support the entity with a composite key", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer func() { _ = tc.Rollback() }()
+		userRoleDataAccess := NewTxDataAccess[UserRoleEntity](tm)
+
+		entity := UserRoleEntity{UserId: 2, RoleId: 3, CreateUserId: P(1)}
+		if _, err := userRoleDataAccess.Create(tc, &entity); err != nil {
+			t.Fatal(err)
+		}
+		cnt, err := userRoleDataAccess.Patch(tc, UserRoleEntity{UserId: 2, RoleId: 3, CreateUserId: P(5)})
+		if err != nil || cnt != 1 {
+			t.Fatalf("Patch failed: %d, %v", cnt, err)
+		}
+		actual, err := userRoleDataAccess.Get(tc, "2,3")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !(actual.GetId() == UserRoleKey{UserId: 2, RoleId: 3} && *actual.CreateUserId == 5) {
+			t.Errorf("Data is not expected: %v", actual)
+		}
+		cnt, err = userRoleDataAccess.Delete(tc, UserRoleKey{UserId: 2, RoleId: 3})
+		if err != nil || cnt != 1 {
+			t.Fatalf("Delete failed: %d, %v", cnt, err)
+		}
+		if _, err = userRoleDataAccess.Get(tc, UserRoleKey{UserId: 2, RoleId: 3}); err != ErrNotFound {
+			t.Errorf("\nExpected: %v\nBut got : %v", ErrNotFound, err)
+		}
+	})
+
+	t.Run("Generate the ids before the insert", func(t *testing.T) {
+		_, err := db.Exec("create table t_device(id varchar(36) primary key, name varchar(30));" +
+			"create table t_order(id integer primary key, memo varchar(30))")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _, _ = db.Exec("drop table t_device; drop table t_order") }()
+		RegisterIdGenerator[OrderEntity](NewSnowflake(1))
+		deviceDataAccess := NewTxDataAccess[DeviceEntity](tm)
+		orderDataAccess := NewTxDataAccess[OrderEntity](tm)
+
+		device := DeviceEntity{Name: P("phone")}
+		if _, err = deviceDataAccess.Create(ctx, &device); err != nil {
+			t.Fatal(err)
+		}
+		if actual, err := deviceDataAccess.Get(ctx, device.Id); err != nil || len(actual.Id) != 36 || *actual.Name != "phone" {
+			t.Errorf("Data is not expected: %v, %v", actual, err)
+		}
+		cnt, err := deviceDataAccess.CreateMulti(ctx, []DeviceEntity{{Name: P("pad")}, {UUIDId: NewUUIDId("watch-1"), Name: P("watch")}})
+		if err != nil || cnt != 2 {
+			t.Fatalf("CreateMulti failed: %d, %v", cnt, err)
+		}
+		if actual, err := deviceDataAccess.Get(ctx, "watch-1"); err != nil || *actual.Name != "watch" {
+			t.Errorf("Data is not expected: %v, %v", actual, err)
+		}
+
+		order := OrderEntity{Memo: P("first")}
+		id, err := orderDataAccess.Create(ctx, &order)
+		if err != nil || id <= 1<<22 || id != order.Id {
+			t.Fatalf("Unexpected id: %d, %d, %v", id, order.Id, err)
+		}
+		if actual, err := orderDataAccess.Get(ctx, id); err != nil || *actual.Memo != "first" {
+			t.Errorf("Data is not expected: %v, %v", actual, err)
+		}
+	})
+
 	t.Run("Create 0 Entity", func(t *testing.T) {
 		tc, err := tm.StartTransaction(ctx)
 		var entities []UserEntity
@@ -204,6 +406,126 @@ func TestSQLite(t *testing.T) {
 		_ = tc.Rollback()
 	})
 
+	t.Run("Update and Patch Entity by the version", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer func() { _ = tc.Rollback() }()
+		versionDataAccess := NewTxDataAccess[VersionedUserEntity](tm)
+
+		entity := VersionedUserEntity{Int64Id: NewInt64Id(2), Score: P(90), Memo: P("Great"), Version: P(0)}
+		cnt, err := versionDataAccess.Update(tc, entity)
+		if err != nil || cnt != 1 {
+			t.Fatalf("Update failed: %v, %v", cnt, err)
+		}
+		cnt, err = versionDataAccess.Update(tc, entity)
+		if !errors.Is(err, ErrOptimisticLock) || cnt != 0 {
+			t.Errorf("Expected ErrOptimisticLock but got: %v, %v", cnt, err)
+		}
+		patch := VersionedUserEntity{Int64Id: NewInt64Id(2), Memo: P("Nice"), Version: P(1)}
+		if cnt, err = versionDataAccess.Patch(tc, patch); err != nil || cnt != 1 {
+			t.Fatalf("Patch failed: %v, %v", cnt, err)
+		}
+		if _, err = versionDataAccess.Patch(tc, patch); !errors.Is(err, ErrOptimisticLock) {
+			t.Errorf("Expected ErrOptimisticLock but got: %v", err)
+		}
+		user, _ := versionDataAccess.Get(tc, 2)
+		if !(*user.Score == 90 && *user.Memo == "Nice" && *user.Version == 2) {
+			t.Errorf("Data is not expected: %v", user)
+		}
+		if _, err = versionDataAccess.Upsert(tc, VersionedUserEntity{Int64Id: NewInt64Id(2), Score: P(80), Version: P(0)}); err != nil {
+			t.Fatalf("Upsert failed: %v", err)
+		}
+		if user, _ = versionDataAccess.Get(tc, 2); !(*user.Score == 80 && *user.Version == 3) {
+			t.Errorf("Data is not expected: %v", user)
+		}
+	})
+
+	t.Run("Fill the audit fields by the clock and the user in ctx", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer func() { _ = tc.Rollback() }()
+		defer func(now func() time.Time) { Now = now }(Now)
+		auditDataAccess := NewTxDataAccess[AuditedUserEntity](tm)
+
+		created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		Now = func() time.Time { return created }
+		entity := AuditedUserEntity{Score: P(70)}
+		if _, err := auditDataAccess.Create(WithUserId(tc, 7), &entity); err != nil {
+			t.Fatal("Error", err)
+		}
+
+		updated := created.Add(time.Hour)
+		Now = func() time.Time { return updated }
+		entity.Score = P(75)
+		if _, err := auditDataAccess.Update(WithUserId(tc, 8), entity); err != nil {
+			t.Fatal("Error", err)
+		}
+
+		user, _ := auditDataAccess.Get(tc, entity.Id)
+		if !(*user.Score == 75 && *user.CreateUserId == 7 && *user.UpdateUserId == 8 &&
+			user.CreateTime.Equal(created) && user.UpdateTime.Equal(updated)) {
+			t.Errorf("Data is not expected: %v", user)
+		}
+
+		upserted := AuditedUserEntity{Int64Id: NewInt64Id(99), Score: P(60)}
+		if _, err := auditDataAccess.UpsertMulti(WithUserId(tc, 9), []AuditedUserEntity{entity, upserted}); err != nil {
+			t.Fatal("Error", err)
+		}
+		user, _ = auditDataAccess.Get(tc, entity.Id)
+		if !(*user.CreateUserId == 7 && *user.UpdateUserId == 9 && user.CreateTime.Equal(created)) {
+			t.Errorf("Data is not expected: %v", user)
+		}
+		user, _ = auditDataAccess.Get(tc, 99)
+		if !(*user.CreateUserId == 9 && *user.UpdateUserId == 9 && user.CreateTime.Equal(updated)) {
+			t.Errorf("Data is not expected: %v", user)
+		}
+	})
+
+	t.Run("Invoke the lifecycle hooks of the entity", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer func() { _ = tc.Rollback() }()
+		hookEvents = nil
+		hookedDataAccess := NewTxDataAccess[HookedUserEntity](tm)
+
+		if _, err := hookedDataAccess.Create(tc, &HookedUserEntity{Score: P(-1)}); err == nil {
+			t.Error("Expected the error from BeforeCreate")
+		}
+		entity := HookedUserEntity{Score: P(70), Memo: P(" Fine ")}
+		if _, err := hookedDataAccess.Create(tc, &entity); err != nil {
+			t.Fatal("Error", err)
+		}
+		_, _ = hookedDataAccess.Update(tc, entity)
+		_, _ = hookedDataAccess.Patch(tc, HookedUserEntity{Int64Id: NewInt64Id(5), Score: P(75)})
+		_, _ = hookedDataAccess.Delete(tc, 5)
+		_, _ = hookedDataAccess.Delete(tc, 100)
+
+		user, _ := userDataAccess.Get(tc, 5)
+		expect := []string{
+			"BeforeCreate in tx 0", "AfterCreate in tx 5",
+			"BeforeUpdate in tx 5", "AfterUpdate in tx 5",
+			"BeforeUpdate in tx 5", "AfterUpdate in tx 5",
+			"BeforeDelete in tx 5", "AfterDelete in tx 5",
+			"BeforeDelete in tx 100",
+		}
+		if !reflect.DeepEqual(hookEvents, expect) || *entity.Memo != "Fine" || user != nil {
+			t.Errorf("\nExpected: %v\nBut got : %v", expect, hookEvents)
+		}
+	})
+
+	t.Run("Wrap TxDataAccess by the interceptors", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer func() { _ = tc.Rollback() }()
+		var operations []string
+		timing := TimingInterceptor(func(inv *Invocation, elapsed time.Duration) {
+			operations = append(operations, inv.Operation)
+		})
+		wrapped := WrapTx(userDataAccess, LoggingInterceptor, timing)
+
+		_, _ = wrapped.Delete(tc, 1)
+		cnt, err := wrapped.Count(tc, UserQuery{})
+		if !(err == nil && cnt == 3 && reflect.DeepEqual(operations, []string{"Delete", "Count"})) {
+			t.Errorf("Data is not expected: %v, %v", cnt, operations)
+		}
+	})
+
 	t.Run("Patch Entity", func(t *testing.T) {
 		tc, err := tm.StartTransaction(ctx)
 		entity := UserEntity{Int64Id: NewInt64Id(2), Score: P(90)}
@@ -291,4 +613,266 @@ func TestSQLite(t *testing.T) {
 			t.Errorf("Data is not expected: %v", roles)
 		}
 	})
+
+	t.Run("Related Query: Page related roles for each user", func(t *testing.T) {
+		userQuery := UserQuery{WithRoles: &RoleQuery{PageQuery: PageQuery{Size: 1, Sort: "id,desc"}}}
+		users, err := userDataAccess.Query(ctx, &userQuery)
+
+		if err != nil {
+			t.Fatal("Error", err)
+		}
+		expect := []RoleEntity{{NewIntId(2), P("vip"), P("VIP"), P(2), nil}}
+		if !(len(users) == 4 &&
+			reflect.DeepEqual(users[0].Roles, expect) &&
+			reflect.DeepEqual(users[3].Roles, expect) &&
+			len(users[1].Roles) == 0) {
+			t.Errorf("Data is not expected: %v", users)
+		}
+	})
+
+	t.Run("Related Query: Query menus with parent and children", func(t *testing.T) {
+		menuDataAccess := NewTxDataAccess[MenuEntity](tm)
+		menuQuery := MenuQuery{WithParent: &MenuQuery{}, WithChildren: &MenuQuery{}}
+		menus, err := menuDataAccess.Query(ctx, &menuQuery)
+
+		if err != nil {
+			t.Fatal("Error", err)
+		}
+		if !(len(menus) == 4 &&
+			menus[0].Parent == nil && len(menus[0].Children) == 2 &&
+			*menus[0].Children[0].Name == "user" && *menus[0].Children[1].Name == "role" &&
+			*menus[1].Parent.Name == "root" && len(menus[1].Children) == 1 &&
+			*menus[3].Parent.Name == "user" && len(menus[3].Children) == 0) {
+			t.Errorf("Data is not expected: %v", menus)
+		}
+	})
+
+	t.Run("Support numbered placeholders and RETURNING id", func(t *testing.T) {
+		pgTm := NewTransactionManager(db)
+		BindDialect(pgTm, &PostgresDialect{})
+		pgDataAccess := NewTxDataAccess[UserEntity](pgTm)
+
+		tc, err := pgTm.StartTransaction(ctx)
+		entity := UserEntity{Score: P(90), Memo: P("Great")}
+		id, err := pgDataAccess.Create(tc, &entity)
+		if err != nil {
+			t.Error("Error", err)
+			return
+		}
+		if !(id == 5 && entity.Id == 5) {
+			t.Errorf("\nExpected: %d\nBut got : %d", 5, id)
+		}
+		users, err := pgDataAccess.Query(tc, UserQuery{IdGt: P(3), ScoreLt: P(95)})
+		if !(err == nil && len(users) == 2 && users[1].Id == 5) {
+			t.Errorf("Data is not expected: %v, %v", users, err)
+		}
+		_ = tc.Rollback()
+	})
+
+	t.Run("Skip reading back the generated ids by the dialect", func(t *testing.T) {
+		noIdTm := NewTransactionManager(db)
+		BindDialect(noIdTm, &noGeneratedIdDialect{})
+		noIdDataAccess := NewTxDataAccess[UserEntity](noIdTm)
+
+		tc, _ := noIdTm.StartTransaction(ctx)
+		defer tc.Rollback()
+
+		entity := UserEntity{Score: P(90), Memo: P("Great")}
+		id, err := noIdDataAccess.Create(tc, &entity)
+		if !(err == nil && id == 0 && entity.Id == 0) {
+			t.Errorf("Create() = %d, %v, id = %d", id, err, entity.Id)
+		}
+		entities := []UserEntity{{Score: P(90), Memo: P("Great")}, {Score: P(55), Memo: P("Bad")}}
+		cnt, err := noIdDataAccess.CreateMulti(tc, entities)
+		if !(err == nil && cnt == 2 && entities[0].Id == 0 && entities[1].Id == 0) {
+			t.Errorf("CreateMulti() = %d, %v, entities = %v", cnt, err, entities)
+		}
+		if cnt, err := noIdDataAccess.Count(tc, UserQuery{}); !(err == nil && cnt == 7) {
+			t.Errorf("Count() = %d, %v", cnt, err)
+		}
+	})
+
+	t.Run("Support quoted identifiers and upsert of SQLiteDialect", func(t *testing.T) {
+		dialect := &SQLiteDialect{}
+		BindDialect(tm, dialect)
+		defer dialectMap.Delete(tm)
+		quotedDataAccess := NewTxDataAccess[UserEntity](tm)
+
+		tc, _ := tm.StartTransaction(ctx)
+		defer tc.Rollback()
+
+		cnt, err := quotedDataAccess.Patch(tc, UserEntity{Int64Id: NewInt64Id(2), Score: P(45)})
+		if !(err == nil && cnt == 1) {
+			t.Fatalf("Patch failed: %d, %v", cnt, err)
+		}
+		columns := []string{dialect.Quote("id"), dialect.Quote("score")}
+		upsert := dialect.BuildUpsert(dialect.Quote("t_user"), columns, columns[:1], columns[1:], "", "", 2)
+		_, err = tc.(*rdbTransactionContext).tx.ExecContext(tc, upsert, 2, 50, 9, 70)
+		if err != nil {
+			t.Fatal("Upsert failed: ", err)
+		}
+		users, err := quotedDataAccess.Query(tc, UserQuery{IdIn: &[]int{2, 9}})
+		if !(err == nil && len(users) == 2 && *users[0].Score == 50 && *users[1].Score == 70) {
+			t.Errorf("Data is not expected: %v, %v", users, err)
+		}
+	})
+
+	t.Run("Support Upsert", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer tc.Rollback()
+
+		cnt, err := userDataAccess.UpsertMulti(tc, []UserEntity{
+			{Int64Id: NewInt64Id(2), Score: P(45), Memo: P("Bad")},
+			{Int64Id: NewInt64Id(9), Score: P(70), Memo: P("New")},
+		})
+		if !(err == nil && cnt == 2) {
+			t.Fatalf("Upsert failed: %d, %v", cnt, err)
+		}
+		users, err := userDataAccess.Query(tc, UserQuery{IdIn: &[]int{2, 9}})
+		if !(err == nil && len(users) == 2 && *users[0].Score == 45 && *users[1].Memo == "New") {
+			t.Errorf("Data is not expected: %v, %v", users, err)
+		}
+	})
+
+	t.Run("Create the upserted entities without an id", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer tc.Rollback()
+
+		cnt, err := userDataAccess.Upsert(tc, UserEntity{Score: P(10), Memo: P("First")})
+		if !(err == nil && cnt == 1) {
+			t.Fatalf("Upsert failed: %d, %v", cnt, err)
+		}
+		entities := []UserEntity{{Score: P(11), Memo: P("Second")}, {Int64Id: NewInt64Id(2), Score: P(45)}}
+		cnt, err = userDataAccess.UpsertMulti(tc, entities)
+		if !(err == nil && cnt == 2 && entities[0].Id == 6) {
+			t.Fatalf("UpsertMulti failed: %d, %v, id = %d", cnt, err, entities[0].Id)
+		}
+		users, err := userDataAccess.Query(tc, UserQuery{IdGt: P(4)})
+		if !(err == nil && len(users) == 2 && *users[0].Memo == "First" && *users[1].Memo == "Second") {
+			t.Errorf("Data is not expected: %v, %v", users, err)
+		}
+	})
+
+	t.Run("Support CursorPage", func(t *testing.T) {
+		query := UserQuery{PageQuery: PageQuery{Size: 2, Sort: "score,desc", After: P("")}}
+		page, err := userDataAccess.CursorPage(ctx, query)
+		if !(err == nil && len(page.List) == 2 && page.List[0].Id == 1 && page.List[1].Id == 4 && page.Next != "") {
+			t.Fatalf("Data is not expected: %v, %v", page, err)
+		}
+
+		query.After = &page.Next
+		page, err = userDataAccess.CursorPage(ctx, query)
+		if !(err == nil && len(page.List) == 2 && page.List[0].Id == 3 && page.List[1].Id == 2) {
+			t.Fatalf("Data is not expected: %v, %v", page, err)
+		}
+
+		query.After = &page.Next
+		page, err = userDataAccess.CursorPage(ctx, query)
+		if !(err == nil && len(page.List) == 0 && page.Next == "") {
+			t.Errorf("Data is not expected: %v, %v", page, err)
+		}
+	})
+
+	t.Run("Page through the NULLs by CursorPage", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer tc.Rollback()
+		if _, err := userDataAccess.Create(tc, &UserEntity{Score: P(70)}); err != nil {
+			t.Fatal(err)
+		}
+		for sort, expect := range map[string][]int64{"memo": {3, 5, 2, 1, 4}, "memo,desc": {4, 1, 2, 3, 5}} {
+			var ids []int64
+			query := UserQuery{PageQuery: PageQuery{Size: 2, Sort: sort, After: P("")}}
+			for {
+				page, err := userDataAccess.CursorPage(tc, query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, user := range page.List {
+					ids = append(ids, user.Id)
+				}
+				if page.Next == "" {
+					break
+				}
+				query.After = &page.Next
+			}
+			if !reflect.DeepEqual(ids, expect) {
+				t.Errorf("%s: Expected: %v, But got : %v", sort, expect, ids)
+			}
+		}
+	})
+
+	t.Run("Support Iterate", func(t *testing.T) {
+		var ids []int64
+		err := userDataAccess.Iterate(ctx, UserQuery{ScoreLt: P(80)}, func(user UserEntity) error {
+			ids = append(ids, user.Id)
+			return nil
+		})
+		if !(err == nil && reflect.DeepEqual(ids, []int64{2, 3, 4})) {
+			t.Errorf("Data is not expected: %v, %v", ids, err)
+		}
+	})
+
+	t.Run("Stop Iterate by the callback error", func(t *testing.T) {
+		stop := errors.New("stop")
+		cnt := 0
+		err := userDataAccess.Iterate(ctx, UserQuery{}, func(user UserEntity) error {
+			cnt++
+			return stop
+		})
+		if !(err == stop && cnt == 1) {
+			t.Errorf("Data is not expected: %d, %v", cnt, err)
+		}
+	})
+
+	t.Run("Stop Iterate by the cancellation", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		cnt := 0
+		err := userDataAccess.Iterate(cancelCtx, UserQuery{}, func(user UserEntity) error {
+			cnt++
+			cancel()
+			return nil
+		})
+		if !(errors.Is(err, context.Canceled) && cnt == 1) {
+			t.Errorf("Data is not expected: %d, %v", cnt, err)
+		}
+	})
+
+	t.Run("Bind dialects to different connections", func(t *testing.T) {
+		analyticsDb := Connect("not-exist.env")
+		defer Disconnect(analyticsDb)
+		BindDialect(analyticsDb, &PostgresDialect{})
+		defer dialectMap.Delete(analyticsDb)
+
+		analyticsDataAccess := NewDataAccess[UserEntity](analyticsDb)
+		mainDataAccess := NewDataAccess[UserEntity](db)
+
+		users, err := analyticsDataAccess.Query(ctx, UserQuery{IdIn: &[]int{1, 3}})
+		if !(err == nil && len(users) == 2) {
+			t.Errorf("Data is not expected: %v, %v", users, err)
+		}
+		cnt, err := mainDataAccess.Count(ctx, UserQuery{IdIn: &[]int{1, 3}})
+		if !(err == nil && cnt == 2) {
+			t.Errorf("Data is not expected: %d, %v", cnt, err)
+		}
+		if resolveDialect(analyticsDb) == resolveDialect(db) {
+			t.Error("Dialects should be bound per connection")
+		}
+		related, err := QueryRelated(ctx, analyticsDb, "SELECT id, score, memo FROM t_user WHERE id IN (?, ?)", []any{1, 3}, reflect.TypeOf(UserEntity{}))
+		if !(err == nil && related.Len() == 2 && related.Index(1).Interface().(UserEntity).Id == 3) {
+			t.Errorf("Data is not expected: %v, %v", related, err)
+		}
+		if _, err = QueryRelated(ctx, db, "SELECT id FROM t_none", nil, reflect.TypeOf(UserEntity{})); err == nil {
+			t.Error("Error is expected for the unknown table")
+		}
+	})
+}
+
+// noGeneratedIdDialect runs on SQLite like a driver unable to read back the ids.
+type noGeneratedIdDialect struct {
+	SQLiteDialect
+}
+
+func (d *noGeneratedIdDialect) ReadsGeneratedId() bool {
+	return false
 }
@@ -23,10 +23,13 @@ func InitDB(db *sql.DB) {
 drop table if exists a_user_and_role;
 drop table if exists t_user;
 drop table if exists t_role;
+drop table if exists t_menu;
 
-create table t_user(id integer constraint user_pk primary key autoincrement, score integer, memo varchar(255));
+create table t_user(id integer constraint user_pk primary key autoincrement, score integer, memo varchar(255), deleted boolean DEFAULT false, version integer DEFAULT 0,
+    create_user_id integer, update_user_id integer, create_time datetime, update_time datetime, tenant_id integer DEFAULT 1);
 create table t_role(id integer constraint role_pk primary key autoincrement, role_name varchar(30), role_code varchar(30), create_user_id integer, valid boolean DEFAULT true);
-create table a_user_and_role (user_id int, role_id int, PRIMARY KEY (user_id, role_id));
+create table a_user_and_role (user_id int, role_id int, create_user_id int, PRIMARY KEY (user_id, role_id));
+create table t_menu(id integer constraint menu_pk primary key autoincrement, parent_id integer, name varchar(30));
 
 INSERT INTO t_user(score, memo) VALUES (85, 'Good'), (40, 'Bad'), (55, null), (62, 'Well');
 INSERT INTO t_role (role_name, role_code, create_user_id) VALUES ('admin', 'ADMIN', 1);
@@ -40,6 +43,8 @@ INSERT INTO a_user_and_role (user_id, role_id) VALUES (1, 2);
 INSERT INTO a_user_and_role (user_id, role_id) VALUES (3, 1);
 INSERT INTO a_user_and_role (user_id, role_id) VALUES (4, 1);
 INSERT INTO a_user_and_role (user_id, role_id) VALUES (4, 2);
+
+INSERT INTO t_menu (parent_id, name) VALUES (null, 'root'), (1, 'user'), (1, 'role'), (2, 'user-list');
 `
 	for _, statement := range strings.Split(sqlText, ";") {
 		_, err := db.Exec(statement)
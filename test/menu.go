@@ -16,6 +16,9 @@ type MenuEntity struct {
 	IntId
 	ParentId *int    `json:"parentId,omitempty"`
 	Name     *string `json:"name,omitempty"`
+
+	Parent   *MenuEntity  `entitypath:"menu,ParentId<-menu" json:"parent,omitempty"`
+	Children []MenuEntity `entitypath:"menu->ParentId,menu" json:"children,omitempty"`
 }
 
 type MenuQuery struct {
@@ -42,4 +45,7 @@ type MenuQuery struct {
 		)
 	)*/
 	User *UserQuery `entitypath:"user,role,perm,menu"`
+
+	WithParent   *MenuQuery
+	WithChildren *MenuQuery
 }
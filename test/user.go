@@ -22,6 +22,18 @@ type UserEntity struct {
 	Roles []RoleEntity `entitypath:"user,role" json:"roles,omitempty"`
 }
 
+// VersionedUserEntity maps to t_user with the version column for optimistic locking.
+type VersionedUserEntity struct {
+	Int64Id
+	Score   *int    `json:"score"`
+	Memo    *string `json:"memo"`
+	Version *int    `json:"version" version:""`
+}
+
+func (u VersionedUserEntity) GetTableName() string {
+	return "t_user"
+}
+
 type UserPatch struct {
 	UserEntity
 	ScoreAe *int
@@ -0,0 +1,44 @@
+/*
+ * The Clear BSD License
+ *
+ * Copyright (c) 2024-2026, DoytoWin, Inc.
+ * All rights reserved.
+ *
+ * This source code is licensed under the BSD-style license found in the
+ * LICENSE file in the root directory of this source tree.
+ */
+
+package test
+
+import . "github.com/doytowin/goooqo/core"
+
+// UserRoleKey is the composite key of UserRoleEntity.
+type UserRoleKey struct {
+	UserId int
+	RoleId int
+}
+
+// UserRoleEntity maps a_user_and_role by the composite key.
+type UserRoleEntity struct {
+	UserId       int  `id:"" json:"userId"`
+	RoleId       int  `id:"" json:"roleId"`
+	CreateUserId *int `json:"createUserId"`
+}
+
+func (e UserRoleEntity) GetTableName() string {
+	return "a_user_and_role"
+}
+
+func (e UserRoleEntity) GetId() any {
+	return UserRoleKey{e.UserId, e.RoleId}
+}
+
+func (e UserRoleEntity) SetId(self any, id any) error {
+	return SetCompositeId(self, id)
+}
+
+type UserRoleQuery struct {
+	PageQuery
+	UserId *int
+	RoleId *int
+}
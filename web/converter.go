@@ -15,15 +15,22 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/doytowin/goooqo/core"
 	log "github.com/sirupsen/logrus"
 )
 
-var converterMap = map[reflect.Type]func(v []string) (any, error){}
+var converterMap sync.Map
 
 func RegisterConverter(typeName reflect.Type, converter func(v []string) (any, error)) {
-	converterMap[typeName] = converter
+	converterMap.Store(typeName, converter)
+}
+
+func loadConverter(typeName reflect.Type) func(v []string) (any, error) {
+	f, _ := converterMap.Load(typeName)
+	c, _ := f.(func(v []string) (any, error))
+	return c
 }
 
 func init() {
@@ -107,7 +114,7 @@ func resolveParam(elem reflect.Value, fieldName string) reflect.Value {
 
 func convertAndSet(field reflect.Value, v []string) {
 	fieldType := field.Type()
-	f := converterMap[fieldType]
+	f := loadConverter(fieldType)
 	if f != nil {
 		v0, err := f(v)
 		if core.NoError(err) || v0 != nil {
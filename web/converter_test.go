@@ -56,7 +56,7 @@ func TestConverter(t *testing.T) {
 		}
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				f := converterMap[tt.args.typeName]
+				f := loadConverter(tt.args.typeName)
 				if f == nil {
 					t.Fatal("unsupported field type: ", tt.args.typeName)
 				}
@@ -13,10 +13,12 @@ package web
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"regexp"
+	"strings"
 
 	. "github.com/doytowin/goooqo/core"
 	log "github.com/sirupsen/logrus"
@@ -33,7 +35,7 @@ func NewRestService[E Entity, Q Query](
 ) http.Handler {
 	return &restService[E, Q]{
 		DataAccess: dataAccess,
-		idRgx:      regexp.MustCompile(prefix + `([\da-fA-F]+)$`),
+		idRgx:      regexp.MustCompile(prefix + `([^/]+)$`),
 	}
 }
 
@@ -59,7 +61,9 @@ func (s *restService[E, Q]) ServeHTTP(writer http.ResponseWriter, request *http.
 		var entities []E
 		err = json.Unmarshal(body, &entities)
 		if NoError(err) {
-			data, err = s.CreateMulti(request.Context(), entities)
+			if _, err = s.CreateMulti(request.Context(), entities); err == nil {
+				data = entities
+			}
 		}
 	} else {
 		query := *new(Q)
@@ -74,6 +78,8 @@ func (s *restService[E, Q]) ServeHTTP(writer http.ResponseWriter, request *http.
 			}
 		} else if request.Method == "DELETE" {
 			data, err = s.DeleteByQuery(request.Context(), query)
+		} else if queryMap.Has("after") {
+			data, err = s.CursorPage(request.Context(), query)
 		} else {
 			data, err = s.Page(request.Context(), query)
 		}
@@ -91,6 +97,9 @@ func (s *restService[E, Q]) process(request *http.Request, id string) (any, erro
 		err = json.Unmarshal(body, &entity)
 		if NoError(err) {
 			err = entity.SetId(&entity, id)
+			if err == nil {
+				err = resolveVersion(request, &entity)
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -102,6 +111,9 @@ func (s *restService[E, Q]) process(request *http.Request, id string) (any, erro
 		err = json.Unmarshal(body, &entity)
 		if NoError(err) {
 			err = entity.SetId(&entity, id)
+			if err == nil {
+				err = resolveVersion(request, &entity)
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -112,8 +124,8 @@ func (s *restService[E, Q]) process(request *http.Request, id string) (any, erro
 	default:
 		var entity *E
 		entity, err = s.Get(request.Context(), id)
-		if entity == nil && NoError(err) {
-			err = errors.New("record not found. id: " + id)
+		if errors.Is(err, ErrNotFound) {
+			err = fmt.Errorf("%w. id: %s", err, id)
 		} else {
 			data = entity
 		}
@@ -121,7 +133,18 @@ func (s *restService[E, Q]) process(request *http.Request, id string) (any, erro
 	return data, err
 }
 
+// resolveVersion sets the version from the If-Match
+// header to the entity as the expected version.
+func resolveVersion(request *http.Request, entity any) error {
+	ifMatch := request.Header.Get("If-Match")
+	if ifMatch == "" {
+		return nil
+	}
+	return SetVersion(entity, strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+}
+
 func writeResult(writer http.ResponseWriter, err error, data any) {
+	status := resolveStatus(err)
 	response := Response{Data: data, Success: NoError(err), Error: ReadError(err)}
 	var bytes []byte
 	if os.Getenv("web_intent") == "true" {
@@ -131,10 +154,28 @@ func writeResult(writer http.ResponseWriter, err error, data any) {
 	}
 	if NoError(err) {
 		writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		writer.WriteHeader(status)
 		_, _ = writer.Write(bytes)
 	}
 }
 
+// resolveStatus reports the errors caused by the
+// request parameters as 400 Bad Request, the absent
+// record as 404 Not Found, and the conflicts of the
+// version or the constraints as 409 Conflict.
+func resolveStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidSort), errors.Is(err, ErrInvalidCursor), errors.Is(err, ErrInvalidId),
+		errors.Is(err, ErrNoCondition), errors.Is(err, ErrNoFieldToUpdate):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrOptimisticLock), errors.Is(err, ErrConstraintViolation):
+		return http.StatusConflict
+	}
+	return http.StatusOK
+}
+
 func ReadError(err error) *string {
 	if err == nil {
 		return nil
@@ -14,6 +14,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
@@ -60,6 +61,10 @@ func TestWeb(t *testing.T) {
 		{"Get", "/user/?memoLike=%25oo%25", `{"data":{"list":[{"id":1,"score":85,"memo":"Good"}],"total":1},"success":true}`},
 		{"Get", "/user/?idIn=1,4", `{"data":{"list":[{"id":1,"score":85,"memo":"Good"},{"id":4,"score":62,"memo":"Well"}],"total":2},"success":true}`},
 		{"Get", "/user/?idIn=1&idIn=4&idIn=a5", `{"data":{"list":[{"id":1,"score":85,"memo":"Good"},{"id":4,"score":62,"memo":"Well"}],"total":2},"success":true}`},
+		{"Get", "/user/?size=2&sort=score,desc&after=", `{"data":{"list":[{"id":1,"score":85,"memo":"Good"},{"id":4,"score":62,"memo":"Well"}],"next":"WzYyLDRd"},"success":true}`},
+		{"Get", "/user/?size=2&sort=score,desc&after=WzYyLDRd", `{"data":{"list":[{"id":3,"score":55,"memo":null},{"id":2,"score":40,"memo":"Bad"}],"next":"WzQwLDJd"},"success":true}`},
+		{"Get", "/user/?size=2&after=bad", `{"data":{"list":null},"success":false,"error":"invalid cursor"}`},
+		{"Get", "/user/?sort=password", `{"data":{"list":null,"total":0},"success":false,"error":"invalid sort column: password"}`},
 		{"Get", "/user/1", `{"data":{"id":1,"score":85,"memo":"Good"},"success":true}`},
 		{"Get", "/user/100", `{"success":false,"error":"record not found. id: 100"}`},
 	}
@@ -77,6 +82,19 @@ func TestWeb(t *testing.T) {
 		})
 	}
 
+	t.Run("Return 400 for invalid sort and cursor", func(t *testing.T) {
+		for _, url := range []string{"/user/?sort=id%3B(select%201)", "/user/?sort=memo,desc&after=bad", "/user/?sort=password&after="} {
+			writer := httptest.NewRecorder()
+			request := httptest.NewRequest("GET", url, nil)
+
+			rs.ServeHTTP(writer, request)
+
+			if writer.Code != http.StatusBadRequest {
+				t.Errorf("\nExpected: %d\nBut got : %d for %s", http.StatusBadRequest, writer.Code, url)
+			}
+		}
+	})
+
 	t.Run("PUT /user/1", func(t *testing.T) {
 		tc, _ := tm.StartTransaction(ctx)
 		defer tc.Rollback()
@@ -106,6 +124,43 @@ func TestWeb(t *testing.T) {
 		}
 	})
 
+	t.Run("Return 404 for the absent record", func(t *testing.T) {
+		writer := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/user/100", nil)
+
+		rs.ServeHTTP(writer, request)
+
+		if writer.Code != http.StatusNotFound {
+			t.Errorf("\nExpected: %d\nBut got : %d", http.StatusNotFound, writer.Code)
+		}
+	})
+
+	t.Run("Return 409 for the conflict of the version in If-Match", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer tc.Rollback()
+		vs := NewRestService[VersionedUserEntity, UserQuery]("/user/", rdb.NewTxDataAccess[VersionedUserEntity](tm))
+
+		for _, tt := range []struct {
+			method, ifMatch, expect string
+			code                    int
+		}{
+			{"PUT", `"0"`, `{"data":1,"success":true}`, http.StatusOK},
+			{"PUT", `"0"`, `{"data":0,"success":false,"error":"optimistic lock failure"}`, http.StatusConflict},
+			{"PATCH", `W/"1"`, `{"data":1,"success":true}`, http.StatusOK},
+			{"PATCH", `1`, `{"data":0,"success":false,"error":"optimistic lock failure"}`, http.StatusConflict},
+		} {
+			writer := httptest.NewRecorder()
+			request := httptest.NewRequest(tt.method, "/user/1", bytes.NewBufferString(`{"score":90}`)).WithContext(tc)
+			request.Header.Set("If-Match", tt.ifMatch)
+
+			vs.ServeHTTP(writer, request)
+
+			if actual := writer.Body.String(); actual != tt.expect || writer.Code != tt.code {
+				t.Errorf("\nExpected: %d %s\nBut got : %d %s", tt.code, tt.expect, writer.Code, actual)
+			}
+		}
+	})
+
 	t.Run("DELETE /user/{id}", func(t *testing.T) {
 		tc, _ := tm.StartTransaction(ctx)
 		defer tc.Rollback()
@@ -222,7 +277,7 @@ func TestWeb(t *testing.T) {
 		rs.ServeHTTP(writer, request)
 
 		actual := writer.Body.String()
-		expect := `{"data":1,"success":true}`
+		expect := `{"data":[{"id":5,"score":60,"memo":"Well"}],"success":true}`
 		if actual != expect {
 			t.Fatalf("\nExpected: %s\nBut got : %s", expect, actual)
 		}
@@ -237,4 +292,62 @@ func TestWeb(t *testing.T) {
 			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
 		}
 	})
+
+	t.Run("Serve the entity with a composite key by /user-role/{userId},{roleId}", func(t *testing.T) {
+		tc, _ := tm.StartTransaction(ctx)
+		defer tc.Rollback()
+		userRoleService := NewRestService[UserRoleEntity, UserRoleQuery]("/user-role/", rdb.NewTxDataAccess[UserRoleEntity](tm))
+
+		writer := httptest.NewRecorder()
+		body := bytes.NewBufferString(`{"createUserId":2}`)
+		request := httptest.NewRequest("PATCH", "/user-role/1,2", body).WithContext(tc)
+		userRoleService.ServeHTTP(writer, request)
+
+		actual := writer.Body.String()
+		expect := `{"data":1,"success":true}`
+		if actual != expect {
+			t.Fatalf("\nExpected: %s\nBut got : %s", expect, actual)
+		}
+
+		writer = httptest.NewRecorder()
+		request = httptest.NewRequest("GET", "/user-role/1,2", nil).WithContext(tc)
+		userRoleService.ServeHTTP(writer, request)
+
+		actual = writer.Body.String()
+		expect = `{"data":{"userId":1,"roleId":2,"createUserId":2},"success":true}`
+		if actual != expect {
+			t.Errorf("\nExpected: %s\nBut got : %s", expect, actual)
+		}
+
+		writer = httptest.NewRecorder()
+		request = httptest.NewRequest("GET", "/user-role/1", nil).WithContext(tc)
+		userRoleService.ServeHTTP(writer, request)
+
+		if writer.Code != http.StatusBadRequest {
+			t.Errorf("\nExpected: %d\nBut got : %d", http.StatusBadRequest, writer.Code)
+		}
+	})
+}
+
+func TestIdRgx(t *testing.T) {
+	rs := NewRestService[UserEntity, UserQuery]("/user/", nil).(*restService[UserEntity, UserQuery])
+	tests := []struct{ path, expect string }{
+		{"/user/12", "12"},
+		{"/user/1,2", "1,2"},
+		{"/user/0190b8a2-7c3e-7a1b-9f4d-3e2a1b0c9d8e", "0190b8a2-7c3e-7a1b-9f4d-3e2a1b0c9d8e"},
+		{"/user/f0rb", "f0rb"},
+		{"/user/", ""},
+		{"/user/1/roles", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			actual := ""
+			if match := rs.idRgx.FindStringSubmatch(tt.path); len(match) > 0 {
+				actual = match[1]
+			}
+			if actual != tt.expect {
+				t.Errorf("\nExpected: %s\nBut got : %s", tt.expect, actual)
+			}
+		})
+	}
 }